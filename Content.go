@@ -1,11 +1,287 @@
 package hmapi
 
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
 type Content struct {
 	Type  MediaType   `json:"type,omitempty"`
 	Value interface{} `json:"value,omitempty"`
+	Href  string      `json:"href,omitempty"`
+	raw   json.RawMessage
+}
+
+func (t *Content) UnmarshalJSON(b []byte) error {
+	var content struct {
+		Type  MediaType       `json:"type"`
+		Value json.RawMessage `json:"value"`
+		Href  string          `json:"href"`
+	}
+
+	if err := json.Unmarshal(b, &content); err != nil {
+		return err
+	}
+
+	t.Type = content.Type
+	t.Href = content.Href
+	t.Value = nil
+	t.raw = content.Value
+
+	if len(content.Value) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(content.Value, &t.Value)
+}
+
+func (t *Content) Time() (time.Time, error) {
+	switch value := t.Value.(type) {
+	case time.Time:
+		return value, nil
+	case string:
+		switch {
+		case mediaTypeEqual(t.Type, MediaTypeHMAPIDate):
+			return time.Parse(DateLayout, value)
+		case mediaTypeEqual(t.Type, MediaTypeHMAPIDateTime):
+			return time.Parse(time.RFC3339Nano, value)
+		}
+	}
+
+	return time.Time{}, &ErrContentType{
+		Type:  t.Type,
+		Value: t.Value,
+		Want:  "time.Time",
+	}
 }
 
-type ContentRequest interface{}
+func (t *Content) Duration() (time.Duration, error) {
+	if !mediaTypeEqual(t.Type, MediaTypeHMAPIDuration) {
+		return 0, &ErrContentType{
+			Type:  t.Type,
+			Value: t.Value,
+			Want:  "time.Duration",
+		}
+	}
+
+	d, err := toDuration(t.Value)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration content: %v", err)
+	}
+
+	return d, nil
+}
+
+func (t *Content) exactValue() (interface{}, error) {
+	if len(t.raw) == 0 {
+		return t.Value, nil
+	}
+
+	return jsonTree(t.raw)
+}
+
+// typed returns a copy holding the value DecodeText would give for the
+// same content fetched out of line.
+func (t *Content) typed() (*Content, error) {
+	encoder, ok := LookupFieldEncoder(t.Type)
+
+	if !ok {
+		return t, nil
+	}
+
+	value, err := t.exactValue()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if items, ok := value.([]interface{}); ok {
+		for i, item := range items {
+			if item != nil {
+				items[i] = typedFieldValue(encoder, item)
+			}
+		}
+	} else if value != nil {
+		value = typedFieldValue(encoder, value)
+	}
+
+	typed := *t
+	typed.Value = value
+
+	return &typed, nil
+}
+
+func (t *Content) DecodeJSON(v interface{}) error {
+	if len(t.raw) > 0 {
+		return json.Unmarshal(t.raw, v)
+	}
+
+	b, err := json.Marshal(t.Value)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+type ContentRequest interface {
+	Get(ctx context.Context) (*Content, error)
+	Open(ctx context.Context) (io.ReadCloser, error)
+}
 
 type contentRequest struct {
+	name     string
+	resource *resourceRequest
+}
+
+func (t *contentRequest) Get(ctx context.Context) (*Content, error) {
+	content, err := t.content(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if content.Href == "" {
+		return content.typed()
+	}
+
+	body, err := t.fetch(ctx, content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer body.Close()
+
+	b, err := ioutil.ReadAll(body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := ParseFieldText(content.Type, string(b))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Content{
+		Type:  content.Type,
+		Value: value,
+		Href:  content.Href,
+	}, nil
+}
+
+func (t *contentRequest) Open(ctx context.Context) (io.ReadCloser, error) {
+	content, err := t.content(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if content.Href != "" {
+		return t.fetch(ctx, content)
+	}
+
+	if mediaTypeEqual(content.Type, MediaTypeOctetStream) {
+		var b []byte
+
+		if err := content.DecodeJSON(&b); err != nil {
+			return nil, err
+		}
+
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+
+	encoder, ok := LookupFieldEncoder(content.Type)
+
+	if !ok {
+		return nil, &ErrUnsupportedMediaType{
+			MediaType: content.Type,
+		}
+	}
+
+	value, err := content.exactValue()
+
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := encoder.EncodeText(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.NopCloser(strings.NewReader(text)), nil
+}
+
+func (t *contentRequest) content(ctx context.Context) (*Content, error) {
+	hmres, err := t.resource.Get(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	content, ok := hmres.Content[t.name]
+
+	if !ok || content == nil {
+		return nil, &ErrResourceNoSuchContent{
+			ContentName: t.name,
+			Resource:    t.resource.path,
+		}
+	}
+
+	resolved := *content
+
+	if resolved.Href != "" {
+		resolved.Href = resolveHref(hmres.Self, resolved.Href)
+	}
+
+	return &resolved, nil
+}
+
+func (t *contentRequest) fetch(ctx context.Context, content *Content) (io.ReadCloser, error) {
+	request, err := http.NewRequest(GET.String(), t.resource.client.url(content.Href), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	request = request.WithContext(ctx)
+
+	if content.Type != "" {
+		request.Header.Set("Accept", content.Type.String())
+	}
+
+	applyHeader(request, t.resource.header)
+
+	resp, err := t.resource.client.do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer drainAndClose(resp.Body)
+		return nil, responseError(request, resp, http.StatusOK, nil)
+	}
+
+	if content.Type != "" {
+		if err := checkContentType(request, resp, []MediaType{content.Type}); err != nil {
+			drainAndClose(resp.Body)
+			return nil, err
+		}
+	}
+
+	return resp.Body, nil
 }
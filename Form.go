@@ -2,11 +2,18 @@ package hmapi
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
-	"strconv"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type FormRequest interface {
@@ -15,41 +22,144 @@ type FormRequest interface {
 	AddFieldAsBool(name string, value bool) FormRequest
 	AddFieldAsOctetStream(name string, value io.Reader) FormRequest
 	AddFieldAsInt(name string, value int) FormRequest
+	AddFieldAsTime(name string, value time.Time) FormRequest
+	AddFieldAsDuration(name string, value time.Duration) FormRequest
+	AddFieldAsJSON(name string, value interface{}) FormRequest
+	Validate(ctx context.Context) error
+	UseDefaults() FormRequest
+	SetStruct(v interface{}) FormRequest
+	WithQuery(query url.Values) FormRequest
+	WithHeader(key string, value string) FormRequest
+	Options(ctx context.Context) (*Options, error)
+	IfMatch() FormRequest
 	Submit(ctx context.Context) (*FormResponse, error)
+	SubmitAsync(ctx context.Context) FormSubmission
+	Start(ctx context.Context) (Operation, error)
 }
 
+// FormResponse is owned by the caller, who must close Body unless one of the
+// consuming helpers (Resource, DecodeJSON) has been called.
 type FormResponse struct {
 	*http.Response
+	client  *client
+	form    *Form
+	errOnce sync.Once
+	err     error
 }
 
+func (t *FormResponse) Err() error {
+	if t.StatusCode >= 200 && t.StatusCode < 300 {
+		return nil
+	}
+
+	t.errOnce.Do(func() {
+		t.err = responseError(t.Request, t.Response, http.StatusOK, t.form)
+	})
+
+	return t.err
+}
+
+func (t *FormResponse) Resource(ctx context.Context) (*Resource, error) {
+	defer drainAndClose(t.Body)
+
+	if err := t.Err(); err != nil {
+		return nil, err
+	}
+
+	switch t.StatusCode {
+	case http.StatusCreated, http.StatusSeeOther:
+		if location := t.Header.Get("Location"); location != "" {
+			return t.client.Resource(t.resolveLocation(location)).Get(ctx)
+		}
+
+	case http.StatusNoContent:
+		return nil, &ErrUnexpectedHTTPResponseStatus{
+			ExpectedStatus: http.StatusOK,
+			ActualStatus:   t.StatusCode,
+			ClientRequest:  redactRequest(t.Request),
+			ClientResponse: redactResponse(t.Response),
+		}
+	}
+
+	return decodeResource(t.Request, t.Response)
+}
+
+func (t *FormResponse) DecodeJSON(v interface{}) error {
+	defer drainAndClose(t.Body)
+
+	if err := t.Err(); err != nil {
+		return err
+	}
+
+	return json.NewDecoder(t.Body).Decode(v)
+}
+
+func (t *FormResponse) resolveLocation(location string) string {
+	if t.Request == nil || t.Request.URL == nil {
+		return location
+	}
+
+	ref, err := url.Parse(location)
+
+	if err != nil {
+		return location
+	}
+
+	return t.Request.URL.ResolveReference(ref).String()
+}
+
+// FormSubmission tracks a form submitted in the background. The submission
+// holds its context until the response body is closed or Cancel is called,
+// so callers must do one of the two once Done is closed.
 type FormSubmission interface {
 	Response() *http.Response
 	Err() error
 	Done() <-chan struct{}
 	Cancel()
+	BytesWritten() int64
 }
 
 type Form struct {
-	Action  string       `json:"action,omitempty"`
-	Method  method       `json:"method"`
-	Type    MediaType    `json:"type,omitempty"`
-	Enctype MediaType    `json:"enctype,omitempty"`
-	Fields  []*FormField `json:"fields,omitempty"`
+	Action    string       `json:"action,omitempty"`
+	Templated bool         `json:"templated,omitempty"`
+	Method    Method       `json:"method"`
+	Type      MediaType    `json:"type,omitempty"`
+	Enctype   MediaType    `json:"enctype,omitempty"`
+	Fields    []*FormField `json:"fields,omitempty"`
 }
 
 type FormField struct {
-	Name     string      `json:"name"`
-	Type     MediaType   `json:"type,omitempty"`
-	Encoding MediaType   `json:"encoding,omitempty"`
-	Required bool        `json:"required"`
-	Multiple bool        `json:"multiple"`
-	Value    interface{} `json:"value,omitempty"`
+	Name        string             `json:"name"`
+	Type        MediaType          `json:"type,omitempty"`
+	Encoding    MediaType          `json:"encoding,omitempty"`
+	Required    bool               `json:"required"`
+	Multiple    bool               `json:"multiple"`
+	Value       interface{}        `json:"value,omitempty"`
+	Fields      []*FormField       `json:"fields,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Options     []*FormFieldOption `json:"options,omitempty"`
+	Min         *float64           `json:"min,omitempty"`
+	Max         *float64           `json:"max,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Default     interface{}        `json:"default,omitempty"`
+}
+
+type FormFieldOption struct {
+	Value interface{} `json:"value"`
+	Label string      `json:"label,omitempty"`
 }
 
 type formRequest struct {
-	name     string
-	fields   []*formField
-	resource *resourceRequest
+	name        string
+	fields      []*formField
+	useDefaults bool
+	ifMatch     bool
+	err         error
+	query       url.Values
+	resource    *resourceRequest
 }
 
 type formField struct {
@@ -58,6 +168,48 @@ type formField struct {
 	value     interface{}
 }
 
+// prefillFields returns the field's published value, falling back to its
+// declared default when no value is published.
+func prefillFields(field *FormField) []*formField {
+	prefill := field.Value
+
+	if prefill == nil {
+		prefill = field.Default
+	}
+
+	if prefill == nil {
+		return nil
+	}
+
+	encoder, ok := LookupFieldEncoder(field.Type)
+
+	if !ok {
+		return nil
+	}
+
+	if _, stream := encoder.(FieldStreamEncoder); stream {
+		return nil
+	}
+
+	values := []interface{}{prefill}
+
+	if items, ok := prefill.([]interface{}); ok && field.Multiple {
+		values = items
+	}
+
+	fields := []*formField{}
+
+	for _, value := range values {
+		fields = append(fields, &formField{
+			name:      field.Name,
+			mediaType: field.Type,
+			value:     typedFieldValue(encoder, value),
+		})
+	}
+
+	return fields
+}
+
 func (t *formRequest) AddField(name string, media MediaType, value interface{}) FormRequest {
 	t.fields = append(t.fields, &formField{
 		name:      name,
@@ -88,27 +240,262 @@ func (t *formRequest) AddFieldAsInt(name string, value int) FormRequest {
 	return t
 }
 
-func (t *formRequest) Submit(ctx context.Context) (retresp *FormResponse, reterr error) {
-	hmres, err := t.resource.Get(ctx)
+func (t *formRequest) AddFieldAsTime(name string, value time.Time) FormRequest {
+	t.AddField(name, MediaTypeHMAPIDateTime, value)
+	return t
+}
+
+func (t *formRequest) AddFieldAsDuration(name string, value time.Duration) FormRequest {
+	t.AddField(name, MediaTypeHMAPIDuration, value)
+	return t
+}
+
+func (t *formRequest) AddFieldAsJSON(name string, value interface{}) FormRequest {
+	switch reflect.Indirect(reflect.ValueOf(value)).Kind() {
+	case reflect.Slice, reflect.Array:
+		t.AddField(name, MediaTypeHMAPIArray, value)
+	default:
+		t.AddField(name, MediaTypeHMAPIObject, value)
+	}
+
+	return t
+}
+
+func (t *formRequest) WithQuery(query url.Values) FormRequest {
+	t.resource = t.resource.clone()
+	t.resource.WithQuery(query)
+
+	if t.query == nil {
+		t.query = url.Values{}
+	}
+
+	for name, values := range query {
+		t.query[name] = append(t.query[name], values...)
+	}
+
+	return t
+}
+
+func (t *formRequest) WithHeader(key string, value string) FormRequest {
+	t.resource = t.resource.clone()
+	t.resource.WithHeader(key, value)
+	return t
+}
+
+func (t *formRequest) IfMatch() FormRequest {
+	t.ifMatch = true
+	return t
+}
+
+func (t *formRequest) UseDefaults() FormRequest {
+	t.useDefaults = true
+	return t
+}
+
+func (t *formRequest) Submit(ctx context.Context) (*FormResponse, error) {
+	return t.submit(ctx, nil)
+}
+
+func (t *formRequest) SubmitAsync(ctx context.Context) FormSubmission {
+	ctx, cancel := context.WithCancel(ctx)
+
+	submission := &formSubmission{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+
+	go func() {
+		defer close(submission.done)
+
+		resp, err := t.submit(ctx, &submission.written)
+
+		if resp == nil {
+			cancel()
+		} else {
+			resp.Body = &cancelOnClose{
+				ReadCloser: resp.Body,
+				cancel:     cancel,
+			}
+		}
+
+		submission.mu.Lock()
+		submission.resp = resp
+		submission.err = err
+		submission.mu.Unlock()
+	}()
+
+	return submission
+}
+
+func (t *formRequest) Start(ctx context.Context) (Operation, error) {
+	resp, err := t.Submit(ctx)
 
 	if err != nil {
 		return nil, err
 	}
 
+	return newOperation(t.resource.client, resp.Response)
+}
+
+func (t *formRequest) Validate(ctx context.Context) error {
+	if t.err != nil {
+		return t.err
+	}
+
+	hmform, err := t.form(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return t.validate(hmform, t.submissionFields(hmform))
+}
+
+func (t *formRequest) submissionFields(form *Form) []*formField {
+	if !t.useDefaults {
+		return t.fields
+	}
+
+	overridden := map[string]bool{}
+
+	for _, field := range t.fields {
+		overridden[field.name] = true
+	}
+
+	fields := []*formField{}
+
+	for _, field := range form.Fields {
+		if overridden[field.Name] {
+			continue
+		}
+
+		fields = append(fields, prefillFields(field)...)
+	}
+
+	return append(fields, t.fields...)
+}
+
+func (t *formRequest) form(ctx context.Context) (*Form, error) {
+	_, hmform, err := t.descriptor(ctx)
+	return hmform, err
+}
+
+func (t *formRequest) descriptor(ctx context.Context) (*Resource, *Form, error) {
+	hmres, err := t.resource.Get(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
 	hmform, ok := hmres.Forms[t.name]
 
 	if !ok {
-		return nil, &ErrResourceNoSuchForm{
+		return nil, nil, &ErrResourceNoSuchForm{
 			FormName: t.name,
 			Resource: t.resource.path,
 		}
 	}
 
+	return hmres, hmform, nil
+}
+
+func (t *formRequest) validate(form *Form, fields []*formField) error {
+	values := map[string][]interface{}{}
+
+	for _, field := range fields {
+		value := field.value
+		encoder, err := t.fieldEncoder(field)
+
+		if err != nil {
+			return err
+		}
+
+		if _, stream := encoder.(FieldStreamEncoder); !stream {
+			if value, err = encoder.EncodeJSON(field.value); err != nil {
+				return t.fieldError(field, err)
+			}
+		}
+
+		values[field.name] = append(values[field.name], value)
+	}
+
+	return form.validate(values)
+}
+
+func (t *formRequest) submit(ctx context.Context, written *int64) (*FormResponse, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	hmres, hmform, err := t.descriptor(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	header := t.resource.header.Clone()
+
+	if t.ifMatch {
+		if hmres.ETag == "" {
+			return nil, &ErrMissingETag{
+				Resource: t.resource.path,
+			}
+		}
+
+		if header == nil {
+			header = http.Header{}
+		}
+
+		header.Set("If-Match", hmres.ETag)
+	}
+
+	fields := t.submissionFields(hmform)
+
+	if err = t.validate(hmform, fields); err != nil {
+		return nil, err
+	}
+
+	action, err := t.action(hmform, fields)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if action, err = applyQuery(t.resource.client.url(action), t.query); err != nil {
+		return nil, err
+	}
+
+	switch Method(strings.ToUpper(hmform.Method.String())) {
+	case CONNECT, TRACE:
+		return nil, &ErrUnsupportedFormMethod{
+			FormName: t.name,
+			Method:   hmform.Method,
+		}
+
+	case GET, HEAD:
+		values, err := t.urlValues(fields)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if action, err = applyQuery(action, values); err != nil {
+			return nil, err
+		}
+
+		return t.submitWithoutBody(ctx, hmform, action, header)
+
+	case DELETE:
+		if len(fields) == 0 {
+			return t.submitWithoutBody(ctx, hmform, action, header)
+		}
+	}
+
 	bodyr, bodyw := io.Pipe()
 
 	request, err := http.NewRequest(
 		hmform.Method.String(),
-		t.resource.client.baseuri+hmform.Action,
+		action,
 		bodyr,
 	)
 
@@ -117,110 +504,378 @@ func (t *formRequest) Submit(ctx context.Context) (retresp *FormResponse, reterr
 	}
 
 	request = request.WithContext(ctx)
+	applyHeader(request, header)
+
+	var writeForm func(io.Writer, *Form, []*formField) error
 
-	switch hmform.Enctype {
-	case MediaTypeMultipartFormData:
+	switch mediaTypeBase(hmform.Enctype) {
+	case mediaTypeBase(MediaTypeMultipartFormData):
 		request.Header.Set("Content-Type", MediaTypeMultipartFormData.String())
+		writeForm = t.writeMultipartForm
+	case MediaTypeFormURLEncoded:
+		request.Header.Set("Content-Type", MediaTypeFormURLEncoded.String())
+		writeForm = t.writeURLEncodedForm
+	case MediaTypeJSON:
+		request.Header.Set("Content-Type", MediaTypeJSON.String())
+		writeForm = t.writeJSONForm
 	default:
 		return nil, &ErrUnsupportedMediaType{
 			MediaType: hmform.Enctype,
 		}
 	}
 
-	chresp := make(chan *http.Response)
-	chresperr := make(chan error)
-	chformerr := make(chan error)
+	var bodywriter io.Writer = bodyw
+
+	if written != nil {
+		bodywriter = &progressWriter{
+			writer:  bodyw,
+			written: written,
+		}
+	}
+
+	chresp := make(chan *http.Response, 1)
+	chresperr := make(chan error, 1)
+	chformerr := make(chan error, 1)
 
 	go func() {
 		resp, err := t.resource.client.do(request)
-		chresperr <- err
+
+		if err != nil {
+			chresperr <- err
+			return
+		}
+
 		chresp <- resp
 	}()
 
 	go func() {
-		switch hmform.Enctype {
-		case MediaTypeMultipartFormData:
-			t.writeMultipartForm(bodyw, hmform, chformerr)
-			bodyw.Close()
-		}
+		err := writeForm(bodywriter, hmform, fields)
+		bodyw.CloseWithError(err)
+		chformerr <- err
 	}()
 
-waitforcomplete:
 	for {
 		select {
 		case formerr := <-chformerr:
-			reterr = formerr
+			if formerr != nil {
+				go discardResponse(chresp, chresperr)
+				return nil, formerr
+			}
 
 		case resperr := <-chresperr:
-			reterr = resperr
+			return nil, resperr
 
 		case resp := <-chresp:
-			retresp = &FormResponse{resp}
-			break waitforcomplete
+			return t.response(ctx, request, resp, hmform)
 
 		case <-ctx.Done():
-			reterr = ctx.Err()
-			break waitforcomplete
+			go discardResponse(chresp, chresperr)
+			return nil, ctx.Err()
 		}
 	}
+}
+
+func (t *formRequest) submitWithoutBody(ctx context.Context, form *Form, action string, header http.Header) (*FormResponse, error) {
+	request, err := http.NewRequest(
+		strings.ToUpper(form.Method.String()),
+		action,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	request = request.WithContext(ctx)
+	applyHeader(request, header)
+
+	resp, err := t.resource.client.do(request)
+
+	if err != nil {
+		return nil, err
+	}
 
-	return retresp, reterr
+	return t.response(ctx, request, resp, form)
 }
 
-func (t *formRequest) writeMultipartForm(writer io.Writer, form *Form, cherr chan error) {
-	mpwriter := multipart.NewWriter(writer)
-	mpwriter.SetBoundary(MultipartFormDataBoundry)
-	defer mpwriter.Close()
+func (t *formRequest) response(ctx context.Context, request *http.Request, resp *http.Response, form *Form) (*FormResponse, error) {
+	if !t.ifMatch || resp.StatusCode != http.StatusPreconditionFailed {
+		return &FormResponse{
+			Response: resp,
+			client:   t.resource.client,
+			form:     form,
+		}, nil
+	}
 
-	for _, field := range t.fields {
-		switch field.mediaType {
-		case MediaTypeOctetStream:
-			fieldreader, ok := field.value.(io.Reader)
+	drainAndClose(resp.Body)
+
+	fresh := t.resource.clone()
+	fresh.resource = nil
+
+	hmres, _, err := fresh.get(ctx)
+
+	return nil, &ErrPreconditionFailed{
+		ETag:           request.Header.Get("If-Match"),
+		Resource:       hmres,
+		RefreshError:   err,
+		ClientRequest:  redactRequest(request),
+		ClientResponse: redactResponse(resp),
+	}
+}
+
+func (t *formRequest) action(form *Form, fields []*formField) (string, error) {
+	if !form.Templated {
+		return form.Action, nil
+	}
 
-			if !ok {
-				cherr <- errors.New("octetstream field is not a io.Reader")
-				return
+	vars := map[string]interface{}{}
+
+	for _, name := range uriTemplateVars(form.Action) {
+		for _, field := range fields {
+			if field.name != name {
+				continue
 			}
 
-			fieldwriter, err := mpwriter.CreateFormField(field.name)
+			encoder, err := t.fieldEncoder(field)
 
 			if err != nil {
-				cherr <- err
-				return
+				return "", err
 			}
 
-			if _, err = io.Copy(fieldwriter, fieldreader); err != nil {
-				cherr <- err
-				return
+			if _, stream := encoder.(FieldStreamEncoder); stream {
+				break
 			}
 
-		case MediaTypeHMAPIInt:
-			if err := mpwriter.WriteField(field.name, strconv.FormatInt(int64(field.value.(int)), 10)); err != nil {
-				cherr <- err
-				return
+			text, err := encoder.EncodeText(field.value)
+
+			if err != nil {
+				return "", t.fieldError(field, err)
 			}
 
-		case MediaTypeHMAPIString:
-			if err := mpwriter.WriteField(field.name, field.value.(string)); err != nil {
-				cherr <- err
-				return
+			vars[name] = text
+			break
+		}
+	}
+
+	return ExpandURITemplate(form.Action, vars)
+}
+
+func discardResponse(chresp chan *http.Response, chresperr chan error) {
+	select {
+	case resp := <-chresp:
+		drainAndClose(resp.Body)
+	case <-chresperr:
+	}
+}
+
+func (t *formRequest) writeMultipartForm(writer io.Writer, form *Form, fields []*formField) error {
+	mpwriter := multipart.NewWriter(writer)
+	mpwriter.SetBoundary(MultipartFormDataBoundry)
+
+	for _, field := range fields {
+		encoder, err := t.fieldEncoder(field)
+
+		if err != nil {
+			return err
+		}
+
+		var fieldwriter io.Writer
+
+		if parter, ok := encoder.(FieldPartEncoder); ok {
+			header := textproto.MIMEHeader{}
+			header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": field.name}))
+			header.Set("Content-Type", parter.PartContentType().String())
+			fieldwriter, err = mpwriter.CreatePart(header)
+		} else {
+			fieldwriter, err = mpwriter.CreateFormField(field.name)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if streamer, ok := encoder.(FieldStreamEncoder); ok {
+			err = streamer.EncodeStream(fieldwriter, field.value)
+		} else {
+			var text string
+
+			if text, err = encoder.EncodeText(field.value); err == nil {
+				_, err = io.WriteString(fieldwriter, text)
 			}
+		}
+
+		if err != nil {
+			return t.fieldError(field, err)
+		}
+	}
+
+	return mpwriter.Close()
+}
+
+func (t *formRequest) writeURLEncodedForm(writer io.Writer, form *Form, fields []*formField) error {
+	values, err := t.urlValues(fields)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(writer, values.Encode())
+	return err
+}
+
+func (t *formRequest) urlValues(fields []*formField) (url.Values, error) {
+	values := url.Values{}
+
+	for _, field := range fields {
+		encoder, err := t.fieldEncoder(field)
+
+		if err != nil {
+			return nil, err
+		}
 
-		case MediaTypeHMAPIBoolean:
-			if err := mpwriter.WriteField(field.name, strconv.FormatBool(field.value.(bool))); err != nil {
-				cherr <- err
-				return
+		if valuer, ok := encoder.(FieldValuesEncoder); ok {
+			if err = valuer.EncodeValues(field.name, field.value, values); err != nil {
+				return nil, t.fieldError(field, err)
 			}
 
+			continue
+		}
+
+		text, err := encoder.EncodeText(field.value)
+
+		if err != nil {
+			return nil, t.fieldError(field, err)
+		}
+
+		values.Add(field.name, text)
+	}
+
+	return values, nil
+}
+
+func (t *formRequest) writeJSONForm(writer io.Writer, form *Form, fields []*formField) error {
+	multiple := map[string]bool{}
+
+	for _, field := range form.Fields {
+		multiple[field.Name] = field.Multiple
+	}
+
+	object := map[string]interface{}{}
+
+	for _, field := range fields {
+		encoder, err := t.fieldEncoder(field)
+
+		if err != nil {
+			return err
+		}
+
+		value, err := encoder.EncodeJSON(field.value)
+
+		if err != nil {
+			return t.fieldError(field, err)
+		}
+
+		existing, exists := object[field.name]
+
+		switch {
+		case multiple[field.name] && exists:
+			object[field.name] = append(existing.([]interface{}), value)
+		case multiple[field.name]:
+			object[field.name] = []interface{}{value}
+		case exists:
+			object[field.name] = []interface{}{existing, value}
+			multiple[field.name] = true
 		default:
-			cherr <- &ErrUnsupportedMediaType{
-				MediaType: form.Enctype,
-			}
-			return
+			object[field.name] = value
 		}
 	}
 
-	cherr <- nil
+	return json.NewEncoder(writer).Encode(object)
+}
+
+func (t *formRequest) fieldEncoder(field *formField) (FieldEncoder, error) {
+	encoder, ok := LookupFieldEncoder(field.mediaType)
+
+	if !ok {
+		return nil, &ErrUnsupportedMediaType{
+			MediaType: field.mediaType,
+		}
+	}
+
+	return encoder, nil
+}
+
+func (t *formRequest) fieldError(field *formField, err error) error {
+	return &ErrInvalidFieldValue{
+		FieldName: field.name,
+		MediaType: field.mediaType,
+		Err:       err,
+	}
+}
+
+type formSubmission struct {
+	// written is updated atomically and must stay first to be 64-bit
+	// aligned on 32-bit platforms.
+	written int64
+	mu      sync.Mutex
+	resp    *FormResponse
+	err     error
+	done    chan struct{}
+	cancel  context.CancelFunc
+}
+
+func (t *formSubmission) Response() *http.Response {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.resp == nil {
+		return nil
+	}
+
+	return t.resp.Response
+}
+
+func (t *formSubmission) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.err
+}
+
+func (t *formSubmission) Done() <-chan struct{} {
+	return t.done
+}
+
+func (t *formSubmission) Cancel() {
+	t.cancel()
+}
+
+func (t *formSubmission) BytesWritten() int64 {
+	return atomic.LoadInt64(&t.written)
+}
+
+// cancelOnClose releases an async submission's context once its response
+// body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (t *cancelOnClose) Close() error {
+	err := t.ReadCloser.Close()
+	t.cancel()
+	return err
+}
+
+type progressWriter struct {
+	writer  io.Writer
+	written *int64
+}
+
+func (t *progressWriter) Write(p []byte) (int, error) {
+	n, err := t.writer.Write(p)
+	atomic.AddInt64(t.written, int64(n))
+	return n, err
 }
 
 type formResponse struct {
@@ -1,62 +1,298 @@
 package hmapi
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 type Link struct {
-	Href     string    `json:"href,omitempty"`
-	Type     MediaType `json:"type,omitempty"`
-	Encoding MediaType `json:"encoding,omitempty"`
+	Href        string    `json:"href,omitempty"`
+	Templated   bool      `json:"templated,omitempty"`
+	Type        MediaType `json:"type,omitempty"`
+	Encoding    MediaType `json:"encoding,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	Title       string    `json:"title,omitempty"`
+	Hreflang    string    `json:"hreflang,omitempty"`
+	Deprecation string    `json:"deprecation,omitempty"`
+	Profile     string    `json:"profile,omitempty"`
+	Methods     []Method  `json:"methods,omitempty"`
+}
+
+func (t *Link) Allows(m Method) bool {
+	if len(t.Methods) == 0 {
+		return true
+	}
+
+	for _, allowed := range t.Methods {
+		if strings.EqualFold(allowed.String(), m.String()) {
+			return true
+		}
+
+		// A target that answers GET also answers HEAD.
+		if strings.EqualFold(allowed.String(), GET.String()) && strings.EqualFold(m.String(), HEAD.String()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *Link) Expand(vars map[string]interface{}) (string, error) {
+	if !t.Templated {
+		return t.Href, nil
+	}
+
+	return ExpandURITemplate(t.Href, vars)
+}
+
+// LinkSet holds the links of a single relation. It decodes from either a
+// single link object or an array of links, and encodes a single link as an
+// object so older clients can still read it.
+type LinkSet []*Link
+
+func (t LinkSet) First() *Link {
+	if len(t) == 0 {
+		return nil
+	}
+
+	return t[0]
+}
+
+func (t LinkSet) Named(name string) *Link {
+	for _, link := range t {
+		if link != nil && link.Name == name {
+			return link
+		}
+	}
+
+	return nil
+}
+
+func (t LinkSet) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+
+	return json.Marshal([]*Link(t))
+}
+
+func (t *LinkSet) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+
+	if len(b) > 0 && b[0] == '[' {
+		var links []*Link
+
+		if err := json.Unmarshal(b, &links); err != nil {
+			return err
+		}
+
+		*t = links
+		return nil
+	}
+
+	var link *Link
+
+	if err := json.Unmarshal(b, &link); err != nil {
+		return err
+	}
+
+	if link == nil {
+		*t = nil
+		return nil
+	}
+
+	*t = LinkSet{link}
+	return nil
 }
 
 type LinkRequest interface {
 	Get(context.Context) (*LinkResponse, error)
+	Head(context.Context) (*LinkHead, error)
+	With(vars map[string]interface{}) LinkRequest
+	Named(name string) LinkRequest
+	WithQuery(query url.Values) LinkRequest
+	WithHeader(key string, value string) LinkRequest
 }
 
+// LinkResponse is owned by the caller, who must close Body.
 type LinkResponse struct {
 	*http.Response
+	Link *Link
+}
+
+type LinkHead struct {
+	Link          *Link
+	ContentLength int64
+	Type          MediaType
+	ETag          string
+	LastModified  time.Time
 }
 
 type linkRequest struct {
 	name     string
+	linkName string
+	vars     map[string]interface{}
+	query    url.Values
 	resource *resourceRequest
 }
 
+func (t *linkRequest) WithQuery(query url.Values) LinkRequest {
+	t.resource = t.resource.clone()
+	t.resource.WithQuery(query)
+
+	if t.query == nil {
+		t.query = url.Values{}
+	}
+
+	for name, values := range query {
+		t.query[name] = append(t.query[name], values...)
+	}
+
+	return t
+}
+
+func (t *linkRequest) WithHeader(key string, value string) LinkRequest {
+	t.resource = t.resource.clone()
+	t.resource.WithHeader(key, value)
+	return t
+}
+
+func (t *linkRequest) Named(name string) LinkRequest {
+	t.linkName = name
+	return t
+}
+
+func (t *linkRequest) With(vars map[string]interface{}) LinkRequest {
+	if t.vars == nil {
+		t.vars = map[string]interface{}{}
+	}
+
+	for name, value := range vars {
+		t.vars[name] = value
+	}
+
+	return t
+}
+
 func (t *linkRequest) Get(ctx context.Context) (*LinkResponse, error) {
-	res, err := t.resource.Get(ctx)
+	request, hmlink, err := t.request(ctx, GET)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.resource.client.do(request)
 
 	if err != nil {
 		return nil, err
 	}
 
-	hmlink, ok := res.Links[t.name]
+	if hmlink.Type != "" && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if err := checkContentType(request, resp, []MediaType{hmlink.Type}); err != nil {
+			drainAndClose(resp.Body)
+			return nil, err
+		}
+	}
+
+	return &LinkResponse{
+		Response: resp,
+		Link:     hmlink,
+	}, nil
+}
+
+func (t *linkRequest) Head(ctx context.Context) (*LinkHead, error) {
+	request, hmlink, err := t.request(ctx, HEAD)
 
-	if !ok {
-		return nil, &ErrResourceNoSuchLink{
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.resource.client.do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer drainAndClose(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, responseError(request, resp, http.StatusOK, nil)
+	}
+
+	head := &LinkHead{
+		Link:          hmlink,
+		ContentLength: resp.ContentLength,
+		Type:          MediaType(resp.Header.Get("Content-Type")),
+		ETag:          resp.Header.Get("ETag"),
+	}
+
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		head.LastModified = modified
+	}
+
+	return head, nil
+}
+
+func (t *linkRequest) request(ctx context.Context, m Method) (*http.Request, *Link, error) {
+	res, err := t.resource.Get(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hmlink := res.Links[t.name].First()
+
+	if t.linkName != "" {
+		hmlink = res.Links[t.name].Named(t.linkName)
+	}
+
+	if hmlink == nil {
+		return nil, nil, &ErrResourceNoSuchLink{
 			LinkName: t.name,
+			Name:     t.linkName,
 			Resource: t.resource.path,
 		}
 	}
 
+	if !hmlink.Allows(m) {
+		return nil, nil, &ErrMethodNotAllowed{
+			Href:    hmlink.Href,
+			Method:  m,
+			Allowed: hmlink.Methods,
+		}
+	}
+
+	href, err := hmlink.Expand(t.vars)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if href, err = applyQuery(t.resource.client.url(href), t.query); err != nil {
+		return nil, nil, err
+	}
+
 	request, err := http.NewRequest(
-		string(GET),
-		t.resource.client.baseuri+hmlink.Href,
+		m.String(),
+		href,
 		nil,
 	)
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	resp, err := t.resource.client.do(request)
+	request = request.WithContext(ctx)
 
-	if err != nil {
-		return nil, err
+	if hmlink.Type != "" {
+		request.Header.Set("Accept", hmlink.Type.String())
 	}
 
-	return &LinkResponse{
-		resp,
-	}, nil
+	applyHeader(request, t.resource.header)
+	return request, hmlink, nil
 }
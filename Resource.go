@@ -2,8 +2,9 @@ package hmapi
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
 type ResourceRequest interface {
@@ -11,17 +12,56 @@ type ResourceRequest interface {
 	Form(name string) FormRequest
 	Link(name string) LinkRequest
 	Content(name string) ContentRequest
+	Embed(rels ...string) ResourceRequest
+	Embedded(ctx context.Context, rel string) ([]ResourceRequest, error)
+	Collection(rel string) Collection
+	WithQuery(query url.Values) ResourceRequest
+	WithHeader(key string, value string) ResourceRequest
+	Options(ctx context.Context) (*Options, error)
 }
 
 type Resource struct {
-	Links   map[string]*Link    `json:"links,omitempty"`
-	Forms   map[string]*Form    `json:"forms,omitempty"`
-	Content map[string]*Content `json:"content,omitempty"`
+	Self        string                 `json:"self,omitempty"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Profile     string                 `json:"profile,omitempty"`
+	Meta        map[string]interface{} `json:"meta,omitempty"`
+	Links       map[string]LinkSet     `json:"links,omitempty"`
+	Forms       map[string]*Form       `json:"forms,omitempty"`
+	Content     map[string]*Content    `json:"content,omitempty"`
+	Embedded    map[string][]*Resource `json:"embedded,omitempty"`
+	ETag        string                 `json:"-"`
+}
+
+func (t *Resource) Link(rel string) *Link {
+	return t.Links[rel].First()
+}
+
+func (t *Resource) AddLink(rel string, links ...*Link) *Resource {
+	if t.Links == nil {
+		t.Links = map[string]LinkSet{}
+	}
+
+	t.Links[rel] = append(t.Links[rel], links...)
+	return t
+}
+
+func (t *Resource) Embed(rel string, resources ...*Resource) *Resource {
+	if t.Embedded == nil {
+		t.Embedded = map[string][]*Resource{}
+	}
+
+	t.Embedded[rel] = append(t.Embedded[rel], resources...)
+	return t
 }
 
 type resourceRequest struct {
-	path   string
-	client *client
+	path     string
+	embed    []string
+	query    url.Values
+	header   http.Header
+	resource *Resource
+	client   *client
 }
 
 func (t *resourceRequest) Form(name string) FormRequest {
@@ -40,43 +80,220 @@ func (t *resourceRequest) Link(name string) LinkRequest {
 }
 
 func (t *resourceRequest) Content(name string) ContentRequest {
-	return &contentRequest{}
+	return &contentRequest{
+		name:     name,
+		resource: t,
+	}
 }
 
-func (t *resourceRequest) Get(ctx context.Context) (*Resource, error) {
-	request, err := http.NewRequest(GET.String(), t.client.baseuri+t.path, nil)
+func (t *resourceRequest) Embed(rels ...string) ResourceRequest {
+	t.embed = append(t.embed, rels...)
+	return t
+}
+
+func (t *resourceRequest) Embedded(ctx context.Context, rel string) ([]ResourceRequest, error) {
+	request := t
+
+	if t.resource == nil {
+		request = t.clone()
+		request.Embed(rel)
+	}
+
+	resource, err := request.Get(ctx)
 
 	if err != nil {
 		return nil, err
 	}
 
+	embedded, ok := resource.Embedded[rel]
+
+	if !ok {
+		return nil, &ErrResourceNoSuchEmbedded{
+			Resource: t.path,
+			Rel:      rel,
+		}
+	}
+
+	requests := []ResourceRequest{}
+
+	for _, resource := range embedded {
+		if resource == nil {
+			continue
+		}
+
+		request, err := t.client.FromResource(resource)
+
+		if err != nil {
+			return nil, err
+		}
+
+		requests = append(requests, request)
+	}
+
+	return requests, nil
+}
+
+func (t *resourceRequest) Get(ctx context.Context) (*Resource, error) {
+	if t.resource != nil {
+		return t.resource, nil
+	}
+
+	resource, _, err := t.get(ctx)
+	return resource, err
+}
+
+func (t *resourceRequest) url() (string, error) {
+	query := url.Values{}
+
+	for name, values := range t.query {
+		query[name] = values
+	}
+
+	if len(t.embed) > 0 {
+		query.Set(EmbedQueryParam, strings.Join(t.embed, ","))
+	}
+
+	return applyQuery(t.client.url(t.path), query)
+}
+
+func (t *resourceRequest) WithQuery(query url.Values) ResourceRequest {
+	if t.query == nil {
+		t.query = url.Values{}
+	}
+
+	for name, values := range query {
+		t.query[name] = append(t.query[name], values...)
+	}
+
+	return t
+}
+
+func (t *resourceRequest) WithHeader(key string, value string) ResourceRequest {
+	if t.header == nil {
+		t.header = http.Header{}
+	}
+
+	t.header.Add(key, value)
+	return t
+}
+
+func (t *resourceRequest) clone() *resourceRequest {
+	clone := *t
+	clone.embed = append([]string{}, t.embed...)
+	clone.query = url.Values{}
+	clone.header = t.header.Clone()
+
+	for name, values := range t.query {
+		clone.query[name] = append([]string{}, values...)
+	}
+
+	return &clone
+}
+
+func applyQuery(href string, query url.Values) (string, error) {
+	if len(query) == 0 {
+		return href, nil
+	}
+
+	u, err := url.Parse(href)
+
+	if err != nil {
+		return "", err
+	}
+
+	values := u.Query()
+
+	for name, extra := range query {
+		values[name] = append(values[name], extra...)
+	}
+
+	u.RawQuery = values.Encode()
+	return u.String(), nil
+}
+
+func applyHeader(request *http.Request, header http.Header) {
+	for key, values := range header {
+		request.Header[key] = append([]string{}, values...)
+	}
+}
+
+func (t *resourceRequest) get(ctx context.Context) (*Resource, *http.Response, error) {
+	href, err := t.url()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	request, err := http.NewRequest(GET.String(), href, nil)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
 	request = request.WithContext(ctx)
+	request.Header.Set("Accept", acceptHeader(codecs.mediaTypes()))
+	applyHeader(request, t.header)
 
 	resp, err := t.client.do(request)
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	defer drainAndClose(resp.Body)
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, &ErrUnexpectedHTTPResponseStatus{
-			ExpectedStatus: http.StatusOK,
-			ActualStatus:   resp.StatusCode,
-			ClientRequest:  request,
-			ClientResponse: resp,
-		}
+		return nil, resp, responseError(request, resp, http.StatusOK, nil)
+	}
+
+	resource, err := decodeResource(request, resp)
+
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return resource, resp, nil
+}
+
+func decodeResource(request *http.Request, resp *http.Response) (*Resource, error) {
+	if err := checkContentType(request, resp, codecs.mediaTypes()); err != nil {
+		return nil, err
+	}
+
+	codec, ok := LookupCodec(MediaType(resp.Header.Get("Content-Type")))
+
+	if !ok {
+		codec, _ = LookupCodec(MediaTypeHMAPIResource)
 	}
 
 	var resource *Resource
 
-	if err = json.NewDecoder(resp.Body).Decode(&resource); err != nil {
+	if err := codec.Decode(resp.Body, &resource); err != nil {
 		return nil, &ErrResourceUnmarshalFailure{
 			UnmarshalError: err,
-			ClientRequest:  request,
-			ClientResponse: resp,
+			ClientRequest:  redactRequest(request),
+			ClientResponse: redactResponse(resp),
 		}
 	}
 
+	if resource == nil {
+		resource = &Resource{}
+	}
+
+	initResource(resp, resource)
+	resource.ETag = resp.Header.Get("ETag")
+	return resource, nil
+}
+
+func initResource(resp *http.Response, resource *Resource) {
+	resource.Self = resolveSelf(resp, resource.Self)
+	initResourceTree(resource)
+}
+
+// initResourceTree resolves embedded self links against their parent. An
+// embedded resource without a self link is left unaddressable rather than
+// inheriting the parent's URL.
+func initResourceTree(resource *Resource) {
 	if resource.Content == nil {
 		resource.Content = map[string]*Content{}
 	}
@@ -86,8 +303,58 @@ func (t *resourceRequest) Get(ctx context.Context) (*Resource, error) {
 	}
 
 	if resource.Links == nil {
-		resource.Links = map[string]*Link{}
+		resource.Links = map[string]LinkSet{}
 	}
 
-	return resource, nil
+	for _, embedded := range resource.Embedded {
+		for _, sub := range embedded {
+			if sub == nil {
+				continue
+			}
+
+			if sub.Self != "" {
+				sub.Self = resolveHref(resource.Self, sub.Self)
+			}
+
+			initResourceTree(sub)
+		}
+	}
+}
+
+func resolveSelf(resp *http.Response, self string) string {
+	if resp.Request == nil || resp.Request.URL == nil {
+		return self
+	}
+
+	base := *resp.Request.URL
+	base.User = nil
+
+	ref, err := url.Parse(self)
+
+	if err != nil {
+		return self
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+func checkContentType(request *http.Request, resp *http.Response, expected []MediaType) error {
+	actual := MediaType(resp.Header.Get("Content-Type"))
+
+	if actual == "" {
+		return nil
+	}
+
+	for _, media := range expected {
+		if mediaTypeMatches(media, actual) {
+			return nil
+		}
+	}
+
+	return &ErrUnexpectedContentType{
+		Expected:       expected,
+		Actual:         actual,
+		ClientRequest:  redactRequest(request),
+		ClientResponse: redactResponse(resp),
+	}
 }
@@ -0,0 +1,553 @@
+package hmapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	readerType   = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	bytesType    = reflect.TypeOf([]byte(nil))
+)
+
+type structField struct {
+	index     []int
+	name      string
+	mediaType MediaType
+	required  bool
+	omitEmpty bool
+	multiple  bool
+}
+
+func structFields(v interface{}) (reflect.Value, []*structField, error) {
+	rv := reflect.ValueOf(v)
+
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return rv, nil, &ErrUnsupportedStructField{
+			Type: reflect.TypeOf(v),
+		}
+	}
+
+	fields := []*structField{}
+
+	for i := 0; i < rv.NumField(); i++ {
+		sf := rv.Type().Field(i)
+		tag := sf.Tag.Get("hmapi")
+
+		if sf.PkgPath != "" || tag == "-" {
+			continue
+		}
+
+		field := &structField{
+			index: sf.Index,
+			name:  sf.Name,
+		}
+
+		opts := strings.Split(tag, ",")
+
+		if opts[0] != "" {
+			field.name = opts[0]
+		}
+
+		for _, opt := range opts[1:] {
+			switch opt {
+			case "required":
+				field.required = true
+			case "omitempty":
+				field.omitEmpty = true
+			}
+		}
+
+		typ := sf.Type
+
+		if typ.Kind() == reflect.Slice && typ != bytesType {
+			field.multiple = true
+			typ = typ.Elem()
+		}
+
+		media, ok := structFieldMediaType(typ)
+
+		if !ok {
+			return rv, nil, &ErrUnsupportedStructField{
+				FieldName: sf.Name,
+				Type:      sf.Type,
+			}
+		}
+
+		field.mediaType = media
+		fields = append(fields, field)
+	}
+
+	return rv, fields, nil
+}
+
+func structFieldMediaType(typ reflect.Type) (MediaType, bool) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == timeType:
+		return MediaTypeHMAPIDateTime, true
+	case typ == durationType:
+		return MediaTypeHMAPIDuration, true
+	case typ == bytesType, typ.Implements(readerType):
+		return MediaTypeOctetStream, true
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return MediaTypeHMAPIString, true
+	case reflect.Bool:
+		return MediaTypeHMAPIBoolean, true
+	case reflect.Int, reflect.Int8, reflect.Int16:
+		return MediaTypeHMAPIInt, true
+	case reflect.Int32:
+		return MediaTypeHMAPIInt32, true
+	case reflect.Int64:
+		return MediaTypeHMAPIInt64, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return MediaTypeHMAPIUInt, true
+	case reflect.Uint32:
+		return MediaTypeHMAPIUInt32, true
+	case reflect.Uint64:
+		return MediaTypeHMAPIUInt64, true
+	case reflect.Float32:
+		return MediaTypeHMAPIFloat32, true
+	case reflect.Float64:
+		return MediaTypeHMAPIFloat64, true
+	case reflect.Struct, reflect.Map:
+		return MediaTypeHMAPIObject, true
+	}
+
+	return "", false
+}
+
+func (t *formRequest) SetStruct(v interface{}) FormRequest {
+	rv, fields, err := structFields(v)
+
+	if err != nil {
+		t.err = err
+		return t
+	}
+
+	missing := []*FieldError{}
+
+	for _, field := range fields {
+		fv := rv.FieldByIndex(field.index)
+
+		if structValueMissing(fv) || (field.omitEmpty && fv.IsZero()) {
+			if field.required {
+				missing = append(missing, &FieldError{
+					Name:   field.name,
+					Reason: "is required",
+				})
+			}
+
+			continue
+		}
+
+		if !field.multiple {
+			t.AddField(field.name, field.mediaType, structFieldValue(fv))
+			continue
+		}
+
+		for i := 0; i < fv.Len(); i++ {
+			if elem := fv.Index(i); !structValueMissing(elem) {
+				t.AddField(field.name, field.mediaType, structFieldValue(elem))
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		t.err = &ErrValidation{
+			FieldErrors: missing,
+		}
+	}
+
+	return t
+}
+
+func structValueMissing(fv reflect.Value) bool {
+	switch fv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map:
+		return fv.IsNil()
+	case reflect.Slice:
+		return fv.Len() == 0
+	}
+
+	return false
+}
+
+func structFieldValue(fv reflect.Value) interface{} {
+	for fv.Kind() == reflect.Ptr && !fv.Type().Implements(readerType) {
+		fv = fv.Elem()
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String()
+	case reflect.Bool:
+		return fv.Bool()
+	}
+
+	return fv.Interface()
+}
+
+func (t *Resource) DecodeContent(v interface{}) error {
+	rv, fields, err := structFields(v)
+
+	if err != nil {
+		return err
+	}
+
+	if !rv.CanSet() {
+		return &ErrUnsupportedStructField{
+			Type: reflect.TypeOf(v),
+		}
+	}
+
+	contenterrs := []*FieldError{}
+
+	for _, field := range fields {
+		content, ok := t.Content[field.name]
+
+		if !ok || content == nil {
+			if field.required {
+				contenterrs = append(contenterrs, &FieldError{
+					Name:   field.name,
+					Reason: "is missing",
+				})
+			}
+
+			continue
+		}
+
+		if err := decodeContentField(rv.FieldByIndex(field.index), field, content); err != nil {
+			contenterrs = append(contenterrs, &FieldError{
+				Name:   field.name,
+				Reason: err.Error(),
+			})
+		}
+	}
+
+	if len(contenterrs) > 0 {
+		return &ErrContentDecode{
+			ContentErrors: contenterrs,
+		}
+	}
+
+	return nil
+}
+
+func decodeContentField(fv reflect.Value, field *structField, content *Content) error {
+	value, err := content.exactValue()
+
+	if err != nil {
+		return err
+	}
+
+	if value == nil {
+		return nil
+	}
+
+	values := []interface{}{value}
+
+	if field.multiple {
+		items, ok := value.([]interface{})
+
+		if !ok {
+			return fmt.Errorf("expected a list of values, got %T", value)
+		}
+
+		values = items
+	}
+
+	encoder, ok := LookupFieldEncoder(content.Type)
+
+	if !ok {
+		encoder, _ = LookupFieldEncoder(field.mediaType)
+	}
+
+	for i, value := range values {
+		if value == nil {
+			return fmt.Errorf("item %v is null", i)
+		}
+
+		values[i] = typedFieldValue(encoder, value)
+	}
+
+	if !field.multiple {
+		return bindValue(fv, field, values[0])
+	}
+
+	slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+
+	for i, value := range values {
+		if err := bindValue(slice.Index(i), field, value); err != nil {
+			return err
+		}
+	}
+
+	fv.Set(slice)
+	return nil
+}
+
+func BindForm(r *http.Request, v interface{}) error {
+	rv, fields, err := structFields(v)
+
+	if err != nil {
+		return err
+	}
+
+	if !rv.CanSet() {
+		return &ErrUnsupportedStructField{
+			Type: reflect.TypeOf(v),
+		}
+	}
+
+	formfields := make([]*FormField, len(fields))
+
+	for i, field := range fields {
+		formfields[i] = &FormField{
+			Name:     field.name,
+			Type:     field.mediaType,
+			Multiple: field.multiple,
+		}
+	}
+
+	raw, err := requestFieldValues(r, formfields)
+
+	if err != nil {
+		return err
+	}
+
+	missing := []*FieldError{}
+
+	for _, field := range fields {
+		values, err := bindFieldValues(r, field, raw[field.name])
+
+		if err != nil {
+			return err
+		}
+
+		if len(values) == 0 {
+			if field.required {
+				missing = append(missing, &FieldError{
+					Name:   field.name,
+					Reason: "is required",
+				})
+			}
+
+			continue
+		}
+
+		fv := rv.FieldByIndex(field.index)
+
+		if !field.multiple {
+			err = bindValue(fv, field, values[0])
+		} else {
+			slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+
+			for i, value := range values {
+				if err = bindValue(slice.Index(i), field, value); err != nil {
+					break
+				}
+			}
+
+			fv.Set(slice)
+		}
+
+		if err != nil {
+			return &ErrInvalidFieldValue{
+				FieldName: field.name,
+				MediaType: field.mediaType,
+				Err:       err,
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return &ErrValidation{
+			FieldErrors: missing,
+		}
+	}
+
+	return nil
+}
+
+func bindFieldValues(r *http.Request, field *structField, raw []interface{}) ([]interface{}, error) {
+	values := []interface{}{}
+
+	for _, value := range raw {
+		typed, err := requestFieldValue(field.mediaType, value)
+
+		if err != nil {
+			return nil, &ErrInvalidFieldValue{
+				FieldName: field.name,
+				MediaType: field.mediaType,
+				Err:       err,
+			}
+		}
+
+		values = append(values, typed)
+	}
+
+	if r.MultipartForm == nil || !mediaTypeEqual(field.mediaType, MediaTypeOctetStream) {
+		return values, nil
+	}
+
+	for _, header := range r.MultipartForm.File[field.name] {
+		file, err := header.Open()
+
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, file)
+	}
+
+	return values, nil
+}
+
+func bindValue(fv reflect.Value, field *structField, value interface{}) error {
+	if fv.Kind() == reflect.Ptr && !fv.Type().Implements(readerType) {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+
+		return bindValue(fv.Elem(), field, value)
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		if fv.Type() != bytesType {
+			value = io.Reader(bytes.NewReader(v))
+		}
+	case io.Reader:
+		if fv.Type() == bytesType {
+			b, err := ioutil.ReadAll(v)
+
+			if err != nil {
+				return err
+			}
+
+			value = b
+		}
+	}
+
+	if value == nil {
+		return fmt.Errorf("cannot use null as %v", fv.Type())
+	}
+
+	rvalue := reflect.ValueOf(value)
+
+	if fv.Kind() == reflect.String && rvalue.Kind() != reflect.String {
+		return fmt.Errorf("cannot use %T as %v", value, fv.Type())
+	}
+
+	switch {
+	case rvalue.Type().AssignableTo(fv.Type()):
+		fv.Set(rvalue)
+		return nil
+
+	case mediaTypeEqual(field.mediaType, MediaTypeHMAPIObject):
+		b, err := json.Marshal(coerceJSONTree(value, fv.Type()))
+
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(b, fv.Addr().Interface())
+
+	case rvalue.Type().ConvertibleTo(fv.Type()):
+		converted := rvalue.Convert(fv.Type())
+
+		if converted.Convert(rvalue.Type()).Interface() != value {
+			return fmt.Errorf("value %v overflows %v", value, fv.Type())
+		}
+
+		fv.Set(converted)
+		return nil
+	}
+
+	return fmt.Errorf("cannot use %T as %v", value, fv.Type())
+}
+
+// coerceJSONTree converts string leaves to the numbers and booleans typ
+// expects. Bracket notation submits every leaf as a string.
+func coerceJSONTree(tree interface{}, typ reflect.Type) interface{} {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch tree := tree.(type) {
+	case map[string]interface{}:
+		for key, child := range tree {
+			if childtype, ok := jsonFieldType(typ, key); ok {
+				tree[key] = coerceJSONTree(child, childtype)
+			}
+		}
+
+	case []interface{}:
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			for i, child := range tree {
+				tree[i] = coerceJSONTree(child, typ.Elem())
+			}
+		}
+
+	case string:
+		switch typ.Kind() {
+		case reflect.Bool:
+			if b, err := strconv.ParseBool(tree); err == nil {
+				return b
+			}
+
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if _, err := strconv.ParseFloat(tree, 64); err == nil {
+				return json.Number(tree)
+			}
+		}
+	}
+
+	return tree
+}
+
+func jsonFieldType(typ reflect.Type, key string) (reflect.Type, bool) {
+	switch typ.Kind() {
+	case reflect.Map:
+		return typ.Elem(), true
+
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			sf := typ.Field(i)
+			name := strings.Split(sf.Tag.Get("json"), ",")[0]
+
+			if sf.PkgPath != "" || name == "-" {
+				continue
+			}
+
+			if name == key || (name == "" && strings.EqualFold(sf.Name, key)) {
+				return sf.Type, true
+			}
+		}
+	}
+
+	return nil, false
+}
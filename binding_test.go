@@ -0,0 +1,319 @@
+package hmapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type testBindingSettings struct {
+	Hostname string            `hmapi:"hostname,required"`
+	Port     uint16            `hmapi:"port"`
+	DHCP     bool              `hmapi:"dhcp"`
+	DNS      []string          `hmapi:"dns"`
+	Expires  *time.Time        `hmapi:"expires"`
+	Firmware io.Reader         `hmapi:"firmware"`
+	Labels   map[string]string `hmapi:"labels,omitempty"`
+	Note     string            `hmapi:"-"`
+}
+
+type Test_FormRequest_when_binding_struct struct {
+	suite.Suite
+}
+
+func (t *Test_FormRequest_when_binding_struct) Test_struct_round_trips_through_submission() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	var bound testBindingSettings
+	var firmware []byte
+	var binderr error
+
+	ret.Mux.HandleFunc("/resource/settings", func(rw http.ResponseWriter, r *http.Request) {
+		if binderr = BindForm(r, &bound); binderr == nil && bound.Firmware != nil {
+			firmware, _ = ioutil.ReadAll(bound.Firmware)
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	form := &Form{
+		Action:  "/resource/settings",
+		Method:  POST,
+		Enctype: MediaTypeMultipartFormData,
+	}
+
+	form.Field("hostname", MediaTypeHMAPIString).Require()
+	form.Field("port", MediaTypeHMAPIUInt)
+	form.Field("dhcp", MediaTypeHMAPIBoolean)
+	form.Field("dns", MediaTypeHMAPIString).Multiple = true
+	form.Field("expires", MediaTypeHMAPIDateTime)
+	form.Field("firmware", MediaTypeOctetStream)
+	form.Field("labels", MediaTypeHMAPIObject)
+
+	ret.Mux.HandleFunc("/resource", writeTestForm(form)).Methods("GET")
+
+	expires := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	_, err := ret.Client.Resource("/resource").Form("settings").
+		SetStruct(&testBindingSettings{
+			Hostname: "device-1",
+			Port:     8080,
+			DHCP:     true,
+			DNS:      []string{"1.1.1.1", "8.8.8.8"},
+			Expires:  &expires,
+			Firmware: strings.NewReader("firmware image"),
+			Labels:   map[string]string{"site": "lab"},
+			Note:     "not sent",
+		}).
+		Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), binderr)
+	assert.Equal(t.T(), "device-1", bound.Hostname)
+	assert.Equal(t.T(), uint16(8080), bound.Port)
+	assert.True(t.T(), bound.DHCP)
+	assert.Equal(t.T(), []string{"1.1.1.1", "8.8.8.8"}, bound.DNS)
+	assert.True(t.T(), expires.Equal(*bound.Expires))
+	assert.Equal(t.T(), "firmware image", string(firmware))
+	assert.Equal(t.T(), map[string]string{"site": "lab"}, bound.Labels)
+	assert.Equal(t.T(), "", bound.Note)
+}
+
+func (t *Test_FormRequest_when_binding_struct) Test_struct_round_trips_through_json_submission() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	var bound testBindingSettings
+	var binderr error
+
+	ret.Mux.HandleFunc("/resource/settings", func(rw http.ResponseWriter, r *http.Request) {
+		binderr = BindForm(r, &bound)
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	form := &Form{
+		Action:  "/resource/settings",
+		Method:  POST,
+		Enctype: MediaTypeJSON,
+	}
+
+	form.Field("hostname", MediaTypeHMAPIString).Require()
+	form.Field("port", MediaTypeHMAPIUInt)
+	form.Field("dhcp", MediaTypeHMAPIBoolean)
+	form.Field("dns", MediaTypeHMAPIString).Multiple = true
+	form.Field("expires", MediaTypeHMAPIDateTime)
+	form.Field("labels", MediaTypeHMAPIObject)
+
+	ret.Mux.HandleFunc("/resource", writeTestForm(form)).Methods("GET")
+
+	expires := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	_, err := ret.Client.Resource("/resource").Form("settings").
+		SetStruct(&testBindingSettings{
+			Hostname: "device-1",
+			Port:     8080,
+			DHCP:     true,
+			DNS:      []string{"1.1.1.1", "8.8.8.8"},
+			Expires:  &expires,
+			Labels:   map[string]string{"site": "lab"},
+		}).
+		Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), binderr)
+	assert.Equal(t.T(), "device-1", bound.Hostname)
+	assert.Equal(t.T(), uint16(8080), bound.Port)
+	assert.True(t.T(), bound.DHCP)
+	assert.Equal(t.T(), []string{"1.1.1.1", "8.8.8.8"}, bound.DNS)
+	assert.True(t.T(), expires.Equal(*bound.Expires))
+	assert.Equal(t.T(), map[string]string{"site": "lab"}, bound.Labels)
+}
+
+func (t *Test_FormRequest_when_binding_struct) Test_bracket_notation_object_bound_with_typed_leaves() {
+	var settings struct {
+		Network struct {
+			MTU  int  `json:"mtu"`
+			DHCP bool `json:"dhcp"`
+		} `hmapi:"network"`
+	}
+
+	request, _ := http.NewRequest(POST.String(), "/resource/settings", strings.NewReader(url.Values{
+		"network[mtu]":  []string{"1500"},
+		"network[dhcp]": []string{"true"},
+	}.Encode()))
+	request.Header.Set("Content-Type", MediaTypeFormURLEncoded.String())
+
+	assert.Nil(t.T(), BindForm(request, &settings))
+	assert.Equal(t.T(), 1500, settings.Network.MTU)
+	assert.True(t.T(), settings.Network.DHCP)
+}
+
+func (t *Test_FormRequest_when_binding_struct) Test_malformed_body_reported_by_server_binding() {
+	var settings testBindingSettings
+
+	request, _ := http.NewRequest(POST.String(), "/resource/settings", strings.NewReader("--missing\r\n"))
+	request.Header.Set("Content-Type", "multipart/form-data; boundary=boundary")
+
+	assert.NotNil(t.T(), BindForm(request, &settings))
+
+	request, _ = http.NewRequest(POST.String(), "/resource/settings", strings.NewReader(`{"hostname":`))
+	request.Header.Set("Content-Type", MediaTypeJSON.String())
+
+	assert.NotNil(t.T(), BindForm(request, &settings))
+}
+
+func (t *Test_FormRequest_when_binding_struct) Test_missing_required_field_rejected_by_client() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	_, err := ret.Client.Resource("/resource").Form("settings").
+		SetStruct(&struct {
+			Hostname *string `hmapi:"hostname,required"`
+		}{}).
+		Submit(context.Background())
+
+	e, ok := err.(*ErrValidation)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), "hostname", e.FieldErrors[0].Name)
+
+	_, err = ret.Client.Resource("/resource").Form("settings").
+		SetStruct(&struct {
+			Handler func() `hmapi:"handler"`
+		}{}).
+		Submit(context.Background())
+
+	_, ok = err.(*ErrUnsupportedStructField)
+	assert.True(t.T(), ok)
+}
+
+func (t *Test_FormRequest_when_binding_struct) Test_null_list_item_reported_as_content_error() {
+	resource := &Resource{}
+
+	assert.Nil(t.T(), json.Unmarshal([]byte(`{"content":{
+		"levels":{"type":"application/vnd.hmapi.int","value":[1,null]}
+	}}`), resource))
+
+	var status struct {
+		Levels []int `hmapi:"levels"`
+	}
+
+	err := resource.DecodeContent(&status)
+
+	e, ok := err.(*ErrContentDecode)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), "levels", e.ContentErrors[0].Name)
+	assert.Equal(t.T(), "item 1 is null", e.ContentErrors[0].Reason)
+}
+
+func (t *Test_FormRequest_when_binding_struct) Test_server_binding_reports_missing_and_invalid_fields() {
+	var settings testBindingSettings
+
+	request, _ := http.NewRequest(POST.String(), "/resource/settings", strings.NewReader(url.Values{
+		"port": []string{"8080"},
+	}.Encode()))
+	request.Header.Set("Content-Type", MediaTypeFormURLEncoded.String())
+
+	err := BindForm(request, &settings)
+
+	e, ok := err.(*ErrValidation)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), "hostname", e.FieldErrors[0].Name)
+	assert.Equal(t.T(), uint16(8080), settings.Port)
+
+	request, _ = http.NewRequest(POST.String(), "/resource/settings", strings.NewReader(url.Values{
+		"hostname": []string{"device-1"},
+		"port":     []string{"70000"},
+	}.Encode()))
+	request.Header.Set("Content-Type", MediaTypeFormURLEncoded.String())
+
+	err = BindForm(request, &settings)
+
+	fielderr, ok := err.(*ErrInvalidFieldValue)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), "port", fielderr.FieldName)
+}
+
+func (t *Test_FormRequest_when_binding_struct) Test_resource_content_decoded_into_struct() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/device/status", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", MediaTypeHMAPIResource.String())
+		io.WriteString(rw, `{"content":{
+			"hostname":{"type":"application/vnd.hmapi.string","value":"device1"},
+			"serial":{"type":"application/vnd.hmapi.int64","value":9007199254740993},
+			"bytes":{"type":"application/vnd.hmapi.uint64","value":18446744073709551615},
+			"load":{"type":"application/vnd.hmapi.float64","value":0.25},
+			"booted":{"type":"application/vnd.hmapi.datetime","value":"2026-01-02T03:04:05Z"},
+			"uptime":{"type":"application/vnd.hmapi.duration","value":"PT1H30M"},
+			"interfaces":{"type":"application/vnd.hmapi.string","value":["eth0","wlan0"]}
+		}}`)
+	}).Methods("GET")
+
+	var status struct {
+		Hostname   string        `hmapi:"hostname,required"`
+		Serial     int64         `hmapi:"serial"`
+		Bytes      uint64        `hmapi:"bytes"`
+		Load       float64       `hmapi:"load"`
+		Booted     time.Time     `hmapi:"booted"`
+		Uptime     time.Duration `hmapi:"uptime"`
+		Interfaces []string      `hmapi:"interfaces"`
+		Temp       *float64      `hmapi:"temp"`
+	}
+
+	resource, err := ret.Client.Resource("/device/status").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), resource.DecodeContent(&status))
+	assert.Equal(t.T(), "device1", status.Hostname)
+	assert.Equal(t.T(), int64(9007199254740993), status.Serial)
+	assert.Equal(t.T(), uint64(18446744073709551615), status.Bytes)
+	assert.Equal(t.T(), 0.25, status.Load)
+	assert.True(t.T(), time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Equal(status.Booted))
+	assert.Equal(t.T(), 90*time.Minute, status.Uptime)
+	assert.Equal(t.T(), []string{"eth0", "wlan0"}, status.Interfaces)
+	assert.Nil(t.T(), status.Temp)
+
+	var mistyped struct {
+		Hostname int    `hmapi:"hostname"`
+		Serial   int32  `hmapi:"serial"`
+		Model    string `hmapi:"model,required"`
+	}
+
+	err = resource.DecodeContent(&mistyped)
+
+	e, ok := err.(*ErrContentDecode)
+	assert.True(t.T(), ok)
+
+	names := []string{}
+
+	for _, contenterr := range e.ContentErrors {
+		names = append(names, contenterr.Name)
+	}
+
+	assert.Equal(t.T(), []string{"hostname", "serial", "model"}, names)
+}
+
+func writeTestForm(form *Form) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{
+			Forms: map[string]*Form{
+				"settings": form,
+			},
+		})
+	}
+}
+
+func TestRunBindingTestSuites(t *testing.T) {
+	suite.Run(t, new(Test_FormRequest_when_binding_struct))
+}
@@ -0,0 +1,427 @@
+package hmapi
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+const (
+	cborMajorUint   = 0
+	cborMajorNegint = 1
+	cborMajorBytes  = 2
+	cborMajorText   = 3
+	cborMajorArray  = 4
+	cborMajorMap    = 5
+	cborMajorTag    = 6
+	cborMajorSimple = 7
+
+	cborIndefinite = 31
+	cborBreak      = 0xff
+)
+
+type cborFormat struct{}
+
+func (t cborFormat) marshal(v interface{}) ([]byte, error) {
+	return t.append(nil, v)
+}
+
+func (t cborFormat) append(b []byte, v interface{}) ([]byte, error) {
+	switch value := v.(type) {
+	case nil:
+		return append(b, 0xf6), nil
+
+	case bool:
+		if value {
+			return append(b, 0xf5), nil
+		}
+
+		return append(b, 0xf4), nil
+
+	case json.Number:
+		if i, err := strconv.ParseInt(value.String(), 10, 64); err == nil {
+			return t.appendInt(b, i), nil
+		}
+
+		if u, err := strconv.ParseUint(value.String(), 10, 64); err == nil {
+			return t.appendHead(b, cborMajorUint, u), nil
+		}
+
+		f, err := value.Float64()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return t.appendFloat(b, f), nil
+
+	case float64:
+		return t.appendFloat(b, value), nil
+
+	case int64:
+		return t.appendInt(b, value), nil
+
+	case uint64:
+		return t.appendHead(b, cborMajorUint, value), nil
+
+	case string:
+		b = t.appendHead(b, cborMajorText, uint64(len(value)))
+		return append(b, value...), nil
+
+	case []byte:
+		b = t.appendHead(b, cborMajorBytes, uint64(len(value)))
+		return append(b, value...), nil
+
+	case []interface{}:
+		var err error
+
+		b = t.appendHead(b, cborMajorArray, uint64(len(value)))
+
+		for _, item := range value {
+			if b, err = t.append(b, item); err != nil {
+				return nil, err
+			}
+		}
+
+		return b, nil
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+
+		for key := range value {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		var err error
+
+		b = t.appendHead(b, cborMajorMap, uint64(len(value)))
+
+		for _, key := range keys {
+			b = t.appendHead(b, cborMajorText, uint64(len(key)))
+			b = append(b, key...)
+
+			if b, err = t.append(b, value[key]); err != nil {
+				return nil, err
+			}
+		}
+
+		return b, nil
+	}
+
+	return nil, fmt.Errorf("cbor: unsupported value of type %T", v)
+}
+
+func (t cborFormat) appendInt(b []byte, i int64) []byte {
+	if i < 0 {
+		return t.appendHead(b, cborMajorNegint, uint64(-(i + 1)))
+	}
+
+	return t.appendHead(b, cborMajorUint, uint64(i))
+}
+
+func (t cborFormat) appendFloat(b []byte, f float64) []byte {
+	b = append(b, cborMajorSimple<<5|27)
+	return binary.BigEndian.AppendUint64(b, math.Float64bits(f))
+}
+
+func (t cborFormat) appendHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major<<5|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major<<5|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major<<5|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major<<5|26), uint32(n))
+	}
+
+	return binary.BigEndian.AppendUint64(append(b, major<<5|27), n)
+}
+
+func (t cborFormat) unmarshal(data []byte) (interface{}, error) {
+	decoder := &cborDecoder{data: data}
+
+	v, err := decoder.decode(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if decoder.pos != len(data) {
+		return nil, decoder.malformed("trailing data")
+	}
+
+	return v, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (t *cborDecoder) malformed(reason string) error {
+	return &ErrCodecMalformed{
+		MediaType: MediaTypeHMAPIResourceCBOR,
+		Reason:    reason,
+	}
+}
+
+func (t *cborDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(t.data)-t.pos < n {
+		return nil, t.malformed("unexpected end of data")
+	}
+
+	b := t.data[t.pos : t.pos+n]
+	t.pos += n
+	return b, nil
+}
+
+func (t *cborDecoder) head() (byte, byte, uint64, error) {
+	b, err := t.next(1)
+
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	major := b[0] >> 5
+	info := b[0] & 0x1f
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		b, err = t.next(1)
+
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		return major, info, uint64(b[0]), nil
+	case info == 25:
+		b, err = t.next(2)
+
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		return major, info, uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err = t.next(4)
+
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		return major, info, uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err = t.next(8)
+
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		return major, info, binary.BigEndian.Uint64(b), nil
+	case info == cborIndefinite:
+		return major, info, 0, nil
+	}
+
+	return 0, 0, 0, t.malformed("reserved additional information")
+}
+
+func (t *cborDecoder) length(n uint64) (int, error) {
+	if n > uint64(len(t.data)-t.pos) {
+		return 0, t.malformed("length exceeds available data")
+	}
+
+	return int(n), nil
+}
+
+func (t *cborDecoder) atBreak() bool {
+	if t.pos < len(t.data) && t.data[t.pos] == cborBreak {
+		t.pos++
+		return true
+	}
+
+	return false
+}
+
+func (t *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxGenericDepth {
+		return nil, t.malformed("maximum nesting depth exceeded")
+	}
+
+	major, info, n, err := t.head()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case cborMajorUint:
+		if info == cborIndefinite {
+			return nil, t.malformed("indefinite length integer")
+		}
+
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+
+		return int64(n), nil
+
+	case cborMajorNegint:
+		if info == cborIndefinite || n > math.MaxInt64 {
+			return nil, t.malformed("negative integer out of range")
+		}
+
+		return -1 - int64(n), nil
+
+	case cborMajorBytes, cborMajorText:
+		var b []byte
+
+		if info == cborIndefinite {
+			for !t.atBreak() {
+				chunk, err := t.decode(depth + 1)
+
+				if err != nil {
+					return nil, err
+				}
+
+				switch chunk := chunk.(type) {
+				case []byte:
+					b = append(b, chunk...)
+				case string:
+					b = append(b, chunk...)
+				default:
+					return nil, t.malformed("invalid indefinite string chunk")
+				}
+			}
+		} else {
+			length, err := t.length(n)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if b, err = t.next(length); err != nil {
+				return nil, err
+			}
+		}
+
+		if major == cborMajorText {
+			return string(b), nil
+		}
+
+		return append([]byte(nil), b...), nil
+
+	case cborMajorArray:
+		if info != cborIndefinite {
+			if _, err := t.length(n); err != nil {
+				return nil, err
+			}
+		}
+
+		items := []interface{}{}
+
+		for i := uint64(0); info == cborIndefinite || i < n; i++ {
+			if info == cborIndefinite && t.atBreak() {
+				break
+			}
+
+			item, err := t.decode(depth + 1)
+
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+
+		return items, nil
+
+	case cborMajorMap:
+		if info != cborIndefinite {
+			if _, err := t.length(n); err != nil {
+				return nil, err
+			}
+		}
+
+		entries := map[string]interface{}{}
+
+		for i := uint64(0); info == cborIndefinite || i < n; i++ {
+			if info == cborIndefinite && t.atBreak() {
+				break
+			}
+
+			key, err := t.decode(depth + 1)
+
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := t.decode(depth + 1)
+
+			if err != nil {
+				return nil, err
+			}
+
+			entries[genericKey(key)] = value
+		}
+
+		return entries, nil
+
+	case cborMajorTag:
+		return t.decode(depth + 1)
+
+	case cborMajorSimple:
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		case 25:
+			return cborFloat16(uint16(n)), nil
+		case 26:
+			return float64(math.Float32frombits(uint32(n))), nil
+		case 27:
+			return math.Float64frombits(n), nil
+		}
+
+		return nil, t.malformed("unsupported simple value")
+	}
+
+	return nil, t.malformed("unknown major type")
+}
+
+func cborFloat16(bits uint16) float64 {
+	exp := int(bits>>10) & 0x1f
+	mant := float64(bits & 0x3ff)
+
+	var value float64
+
+	switch exp {
+	case 0:
+		value = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			value = math.Inf(1)
+		} else {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mant+1024, exp-25)
+	}
+
+	if bits&0x8000 != 0 {
+		return -value
+	}
+
+	return value
+}
@@ -3,11 +3,17 @@ package hmapi
 import (
 	"crypto/tls"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 type Client interface {
 	Resource(path string) ResourceRequest
+	FromResource(resource *Resource) (ResourceRequest, error)
 }
 
 type ClientConfig struct {
@@ -68,7 +74,60 @@ func (t *client) Resource(path string) ResourceRequest {
 	}
 }
 
+func (t *client) FromResource(resource *Resource) (ResourceRequest, error) {
+	if resource == nil || resource.Self == "" {
+		return nil, &ErrResourceNoSelf{
+			Resource: resource,
+		}
+	}
+
+	return &resourceRequest{
+		client:   t,
+		path:     resource.Self,
+		resource: resource,
+	}, nil
+}
+
+// do signs requests addressed to the configured host only, so hrefs that
+// point at another origin never receive the client's credentials.
 func (t *client) do(r *http.Request) (*http.Response, error) {
-	t.config.Auth.Sign(r)
+	if t.sameOrigin(r.URL) {
+		t.config.Auth.Sign(r)
+	}
+
 	return t.config.HTTPClient.Do(r)
 }
+
+func (t *client) sameOrigin(u *url.URL) bool {
+	if u == nil || !strings.EqualFold(u.Scheme, t.config.Scheme.String()) {
+		return false
+	}
+
+	port := u.Port()
+
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		}
+	}
+
+	return strings.EqualFold(u.Hostname(), t.config.Host) && port == strconv.Itoa(t.config.Port)
+}
+
+func (t *client) url(href string) string {
+	if u, err := url.Parse(href); err == nil && u.IsAbs() {
+		return href
+	}
+
+	return t.baseuri + href
+}
+
+const maxDrainBytes = 64 * 1024
+
+func drainAndClose(body io.ReadCloser) {
+	io.Copy(ioutil.Discard, io.LimitReader(body, maxDrainBytes))
+	body.Close()
+}
@@ -1,6 +1,7 @@
 package hmapi
 
 import (
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -20,6 +21,27 @@ func (t *when_constructing_new_hmapi_client) Test_with_empty_config_returns_expe
 	assert.IsType(t.T(), new(AuthNone), c.config.Auth)
 }
 
+func (t *when_constructing_new_hmapi_client) Test_signs_requests_for_configured_origin_only() {
+	c := NewClient(&ClientConfig{Host: "device", Port: 8443, Scheme: HTTPS}).(*client)
+
+	for href, expected := range map[string]bool{
+		"https://device:8443/resource":  true,
+		"HTTPS://DEVICE:8443/resource":  true,
+		"http://device:8443/resource":   false,
+		"https://device/resource":       false,
+		"https://other:8443/resource":   false,
+		"https://device:8443.evil/path": false,
+	} {
+		u, _ := url.Parse(href)
+		assert.Equal(t.T(), expected, c.sameOrigin(u), href)
+	}
+
+	c = NewClient(&ClientConfig{Host: "device", Port: 443, Scheme: HTTPS}).(*client)
+	u, _ := url.Parse("https://device/resource")
+
+	assert.True(t.T(), c.sameOrigin(u))
+}
+
 func TestRunClientTestSuites(t *testing.T) {
 	suite.Run(t, new(when_constructing_new_hmapi_client))
 }
@@ -0,0 +1,166 @@
+package hmapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+type Codec interface {
+	MediaType() MediaType
+	Encode(w io.Writer, v interface{}) error
+	Decode(r io.Reader, v interface{}) error
+}
+
+var codecs = &codecRegistry{
+	byMediaType: map[MediaType]Codec{},
+}
+
+func init() {
+	RegisterCodec(&jsonCodec{mediaType: MediaTypeHMAPIResource})
+	RegisterCodec(&jsonCodec{mediaType: MediaTypeJSON})
+	RegisterCodec(&genericCodec{mediaType: MediaTypeHMAPIResourceCBOR, format: cborFormat{}})
+	RegisterCodec(&genericCodec{mediaType: MediaTypeHMAPIResourceMsgPack, format: msgpackFormat{}})
+}
+
+func RegisterCodec(codec Codec) {
+	codecs.register(codec)
+}
+
+func LookupCodec(media MediaType) (Codec, bool) {
+	return codecs.lookup(media)
+}
+
+type codecRegistry struct {
+	mu          sync.RWMutex
+	order       []MediaType
+	byMediaType map[MediaType]Codec
+}
+
+func (t *codecRegistry) register(codec Codec) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	media := mediaTypeBase(codec.MediaType())
+
+	if _, ok := t.byMediaType[media]; !ok {
+		t.order = append(t.order, codec.MediaType())
+	}
+
+	t.byMediaType[media] = codec
+}
+
+func (t *codecRegistry) lookup(media MediaType) (Codec, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	codec, ok := t.byMediaType[mediaTypeBase(media)]
+	return codec, ok
+}
+
+func (t *codecRegistry) mediaTypes() []MediaType {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	medias := make([]MediaType, len(t.order))
+	copy(medias, t.order)
+	return medias
+}
+
+type jsonCodec struct {
+	mediaType MediaType
+}
+
+func (t *jsonCodec) MediaType() MediaType {
+	return t.mediaType
+}
+
+func (t *jsonCodec) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (t *jsonCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// genericFormat encodes and decodes the generic value tree produced by
+// encoding/json (nil, bool, json.Number, string, []interface{} and
+// map[string]interface{}), allowing binary formats to reuse the json struct
+// tags of the hmapi types.
+type genericFormat interface {
+	marshal(v interface{}) ([]byte, error)
+	unmarshal(data []byte) (interface{}, error)
+}
+
+type genericCodec struct {
+	mediaType MediaType
+	format    genericFormat
+}
+
+func (t *genericCodec) MediaType() MediaType {
+	return t.mediaType
+}
+
+func (t *genericCodec) Encode(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var tree interface{}
+
+	if err = decoder.Decode(&tree); err != nil {
+		return err
+	}
+
+	data, err := t.format.marshal(tree)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (t *genericCodec) Decode(r io.Reader, v interface{}) error {
+	data, err := ioutil.ReadAll(r)
+
+	if err != nil {
+		return err
+	}
+
+	tree, err := t.format.unmarshal(data)
+
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(tree)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+const maxGenericDepth = 128
+
+func genericKey(key interface{}) string {
+	switch key := key.(type) {
+	case string:
+		return key
+	case []byte:
+		return string(key)
+	}
+
+	return fmt.Sprint(key)
+}
@@ -0,0 +1,121 @@
+package hmapi
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type Test_Codec_when_round_tripping struct {
+	suite.Suite
+}
+
+func (t *Test_Codec_when_round_tripping) Test_all_codecs_produce_identical_resources() {
+	var expected *Resource
+
+	for _, media := range codecs.mediaTypes() {
+		codec, ok := LookupCodec(media)
+		assert.True(t.T(), ok)
+
+		buf := &bytes.Buffer{}
+
+		assert.Nil(t.T(), codec.Encode(buf, t.testResource()), media.String())
+
+		var actual *Resource
+
+		assert.Nil(t.T(), codec.Decode(buf, &actual), media.String())
+
+		if expected == nil {
+			expected = actual
+			continue
+		}
+
+		assert.Equal(t.T(), expected, actual, media.String())
+	}
+
+	assert.Equal(t.T(), "device1", expected.Content["hostname"].Value)
+	assert.Equal(t.T(), POST, expected.Forms["reboot"].Method)
+}
+
+func (t *Test_Codec_when_round_tripping) Test_client_decodes_negotiated_binary_codecs() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	for _, media := range []MediaType{MediaTypeHMAPIResourceCBOR, MediaTypeHMAPIResourceMsgPack} {
+		codec, _ := LookupCodec(media)
+
+		ret.Mux.HandleFunc("/"+codec.MediaType().String(), func(rw http.ResponseWriter, r *http.Request) {
+			rw.Header().Set("Content-Type", codec.MediaType().String())
+			codec.Encode(rw, t.testResource())
+		})
+
+		resource, err := ret.Client.Resource("/" + media.String()).Get(context.Background())
+
+		assert.Nil(t.T(), err, media.String())
+		assert.Equal(t.T(), "device1", resource.Content["hostname"].Value, media.String())
+		assert.Equal(t.T(), "/device/reboot", resource.Forms["reboot"].Action, media.String())
+	}
+}
+
+func (t *Test_Codec_when_round_tripping) Test_negotiates_codec_from_accept() {
+	request, _ := http.NewRequest(GET.String(), "/", nil)
+
+	request.Header.Set("Accept", "application/vnd.hmapi.Resource+json;q=0.5, application/vnd.hmapi.Resource+msgpack")
+	assert.Equal(t.T(), MediaTypeHMAPIResourceMsgPack, NegotiateCodec(request).MediaType())
+
+	request.Header.Set("Accept", "*/*")
+	assert.Equal(t.T(), MediaTypeHMAPIResource, NegotiateCodec(request).MediaType())
+
+	request.Header.Set("Accept", "text/html, application/vnd.hmapi.Resource+cbor;q=0.1")
+	assert.Equal(t.T(), MediaTypeHMAPIResourceCBOR, NegotiateCodec(request).MediaType())
+}
+
+func (t *Test_Codec_when_round_tripping) Test_rejects_truncated_binary_documents() {
+	for _, media := range []MediaType{MediaTypeHMAPIResourceCBOR, MediaTypeHMAPIResourceMsgPack} {
+		codec, _ := LookupCodec(media)
+		buf := &bytes.Buffer{}
+
+		codec.Encode(buf, t.testResource())
+
+		var resource *Resource
+
+		err := codec.Decode(bytes.NewReader(buf.Bytes()[:buf.Len()/2]), &resource)
+
+		_, ok := err.(*ErrCodecMalformed)
+		assert.True(t.T(), ok, media.String())
+	}
+}
+
+func (t *Test_Codec_when_round_tripping) testResource() *Resource {
+	return &Resource{
+		Links: map[string]LinkSet{
+			"logs": LinkSet{&Link{Href: "/device/logs", Type: MediaTypeTextPlain}},
+		},
+		Forms: map[string]*Form{
+			"reboot": &Form{
+				Action:  "/device/reboot",
+				Method:  POST,
+				Enctype: MediaTypeMultipartFormData,
+				Fields: []*FormField{
+					&FormField{Name: "delay", Type: MediaTypeHMAPIInt, Value: 30},
+					&FormField{Name: "force", Type: MediaTypeHMAPIBoolean, Required: true},
+				},
+			},
+		},
+		Content: map[string]*Content{
+			"hostname":  &Content{Type: MediaTypeHMAPIString, Value: "device1"},
+			"uptime":    &Content{Type: MediaTypeHMAPIInt64, Value: int64(-86400)},
+			"load":      &Content{Type: MediaTypeHMAPIFloat64, Value: 0.25},
+			"online":    &Content{Type: MediaTypeHMAPIBoolean, Value: true},
+			"interface": &Content{Type: MediaTypeHMAPIString, Value: []interface{}{"eth0", "wlan0"}},
+		},
+	}
+}
+
+func TestRunCodecTestSuites(t *testing.T) {
+	suite.Run(t, new(Test_Codec_when_round_tripping))
+}
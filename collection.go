@@ -0,0 +1,173 @@
+package hmapi
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+const (
+	CollectionLinkNext = "next"
+	CollectionLinkPrev = "prev"
+)
+
+type Collection interface {
+	PageSize(size int) Collection
+	Next(ctx context.Context) bool
+	Item() ResourceRequest
+	Err() error
+}
+
+type collection struct {
+	rel      string
+	pageSize int
+	first    *resourceRequest
+	page     *Resource
+	visited  map[string]bool
+	items    []ResourceRequest
+	item     ResourceRequest
+	done     bool
+	err      error
+}
+
+func (t *resourceRequest) Collection(rel string) Collection {
+	return &collection{
+		rel:     rel,
+		first:   t,
+		visited: map[string]bool{},
+	}
+}
+
+func (t *collection) PageSize(size int) Collection {
+	t.pageSize = size
+	return t
+}
+
+func (t *collection) Next(ctx context.Context) bool {
+	for len(t.items) == 0 {
+		if t.done || t.err != nil {
+			t.item = nil
+			return false
+		}
+
+		if err := t.fetch(ctx); err != nil {
+			t.err = err
+		}
+	}
+
+	t.item = t.items[0]
+	t.items = t.items[1:]
+	return true
+}
+
+func (t *collection) Item() ResourceRequest {
+	return t.item
+}
+
+func (t *collection) Err() error {
+	return t.err
+}
+
+func (t *collection) fetch(ctx context.Context) error {
+	request, ok := t.nextPage()
+
+	if !ok {
+		t.done = true
+		return nil
+	}
+
+	page, err := request.Get(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	t.page = page
+	t.visited[page.Self] = true
+
+	if embedded, ok := page.Embedded[t.rel]; ok {
+		for _, resource := range embedded {
+			if resource == nil {
+				continue
+			}
+
+			if resource.Self == "" {
+				return &ErrResourceNoSelf{
+					Resource: resource,
+				}
+			}
+
+			t.items = append(t.items, t.request(resource.Self, resource))
+		}
+
+		return nil
+	}
+
+	for _, link := range page.Links[t.rel] {
+		if link != nil && link.Href != "" {
+			t.items = append(t.items, t.request(resolveHref(page.Self, link.Href), nil))
+		}
+	}
+
+	return nil
+}
+
+func (t *collection) nextPage() (*resourceRequest, bool) {
+	if t.page == nil {
+		request := t.first.clone()
+		request.embed = append(request.embed, t.rel)
+
+		if t.pageSize > 0 {
+			request.query.Set(PageSizeQueryParam, strconv.Itoa(t.pageSize))
+		}
+
+		return request, true
+	}
+
+	next := t.page.Link(CollectionLinkNext)
+
+	if next == nil || next.Href == "" {
+		return nil, false
+	}
+
+	href := resolveHref(t.page.Self, next.Href)
+
+	if t.visited[href] {
+		return nil, false
+	}
+
+	t.visited[href] = true
+
+	request := t.request(href, nil)
+	request.embed = []string{t.rel}
+
+	return request, true
+}
+
+// request derives a request for a page or item from the first request so the
+// caller's headers reach every fetch. The query is not carried over, the
+// collection's hrefs already encode their own.
+func (t *collection) request(path string, resource *Resource) *resourceRequest {
+	request := t.first.clone()
+	request.path = path
+	request.embed = nil
+	request.query = url.Values{}
+	request.resource = resource
+	return request
+}
+
+func resolveHref(base string, href string) string {
+	baseurl, err := url.Parse(base)
+
+	if err != nil || !baseurl.IsAbs() {
+		return href
+	}
+
+	ref, err := url.Parse(href)
+
+	if err != nil {
+		return href
+	}
+
+	return baseurl.ResolveReference(ref).String()
+}
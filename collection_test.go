@@ -0,0 +1,168 @@
+package hmapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type Test_Collection_when_iterating struct {
+	suite.Suite
+}
+
+func (t *Test_Collection_when_iterating) Test_iterates_embedded_items_across_pages() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	var pages []string
+
+	ret.Mux.HandleFunc("/devices", t.servePages(5, &pages)).Methods("GET")
+
+	collection := ret.Client.Resource("/devices").Collection("devices").PageSize(2)
+	names := []string{}
+
+	for collection.Next(context.Background()) {
+		resource, err := collection.Item().Get(context.Background())
+
+		assert.Nil(t.T(), err)
+		assert.Equal(t.T(), fmt.Sprintf("%v/devices/%v", ret.Server.URL, len(names)), resource.Self)
+		names = append(names, resource.Title)
+	}
+
+	assert.Nil(t.T(), collection.Err())
+	assert.Equal(t.T(), []string{"device-0", "device-1", "device-2", "device-3", "device-4"}, names)
+	assert.Equal(t.T(), []string{"0:2", "2:2", "4:2"}, pages)
+}
+
+func (t *Test_Collection_when_iterating) Test_iterates_linked_items() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	var pages []string
+
+	ret.Mux.HandleFunc("/devices", t.servePages(3, &pages)).Methods("GET")
+	ret.Mux.HandleFunc("/devices/{id}", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{Title: "device-" + mux.Vars(r)["id"]})
+	}).Methods("GET")
+
+	collection := ret.Client.Resource("/devices").Collection("item")
+	names := []string{}
+
+	for collection.Next(context.Background()) {
+		resource, err := collection.Item().Get(context.Background())
+
+		assert.Nil(t.T(), err)
+		names = append(names, resource.Title)
+	}
+
+	assert.Nil(t.T(), collection.Err())
+	assert.Equal(t.T(), []string{"device-0", "device-1", "device-2"}, names)
+	assert.Equal(t.T(), []string{"0:2", "2:2"}, pages)
+}
+
+func (t *Test_Collection_when_iterating) Test_headers_sent_with_every_item_request() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	var pages []string
+	var auth []string
+
+	ret.Mux.HandleFunc("/devices", t.servePages(3, &pages)).Methods("GET")
+	ret.Mux.HandleFunc("/devices/{id}", func(rw http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		writeTestResource(rw, &Resource{Title: "device-" + mux.Vars(r)["id"]})
+	}).Methods("GET")
+
+	collection := ret.Client.Resource("/devices").
+		WithHeader("Authorization", "Bearer token").
+		Collection("item")
+
+	for collection.Next(context.Background()) {
+		_, err := collection.Item().Get(context.Background())
+		assert.Nil(t.T(), err)
+	}
+
+	assert.Nil(t.T(), collection.Err())
+	assert.Equal(t.T(), []string{"Bearer token", "Bearer token", "Bearer token"}, auth)
+}
+
+func (t *Test_Collection_when_iterating) Test_stops_early_without_fetching_more_pages() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	var pages []string
+
+	ret.Mux.HandleFunc("/devices", t.servePages(10, &pages)).Methods("GET")
+
+	collection := ret.Client.Resource("/devices").Collection("devices").PageSize(3)
+	count := 0
+
+	for collection.Next(context.Background()) {
+		if count++; count == 4 {
+			break
+		}
+	}
+
+	assert.Equal(t.T(), []string{"0:3", "3:3"}, pages)
+}
+
+func (t *Test_Collection_when_iterating) Test_reports_page_errors() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/devices", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}).Methods("GET")
+
+	collection := ret.Client.Resource("/devices").Collection("devices")
+
+	assert.False(t.T(), collection.Next(context.Background()))
+	assert.NotNil(t.T(), collection.Err())
+	assert.Nil(t.T(), collection.Item())
+}
+
+// servePages publishes total devices, embedding them when requested and
+// linking them as "item" otherwise. Each request is recorded as "offset:limit".
+func (t *Test_Collection_when_iterating) servePages(total int, pages *[]string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, err := strconv.Atoi(r.URL.Query().Get(PageSizeQueryParam))
+
+		if err != nil {
+			limit = 2
+		}
+
+		*pages = append(*pages, fmt.Sprintf("%v:%v", offset, limit))
+
+		resource := &Resource{}
+
+		for i := offset; i < offset+limit && i < total; i++ {
+			if EmbedRequested(r, "devices") {
+				resource.Embed("devices", &Resource{
+					Self:  fmt.Sprintf("devices/%v", i),
+					Title: fmt.Sprintf("device-%v", i),
+				})
+			} else {
+				resource.AddLink("item", &Link{Href: fmt.Sprintf("devices/%v", i)})
+			}
+		}
+
+		if offset+limit < total {
+			resource.AddLink(CollectionLinkNext, &Link{
+				Href: fmt.Sprintf("/devices?offset=%v&%v=%v", offset+limit, PageSizeQueryParam, limit),
+			})
+		}
+
+		writeTestResource(rw, resource)
+	}
+}
+
+func TestRunCollectionTestSuites(t *testing.T) {
+	suite.Run(t, new(Test_Collection_when_iterating))
+}
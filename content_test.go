@@ -0,0 +1,186 @@
+package hmapi
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type Test_ContentRequest_when_fetching struct {
+	suite.Suite
+}
+
+func (t *Test_ContentRequest_when_fetching) Test_inline_and_out_of_line_content() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	screenshot := bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 1024)
+	config := "hostname=device-1\nport=8080\n"
+
+	var fetched []string
+
+	ret.Mux.HandleFunc("/device", func(rw http.ResponseWriter, r *http.Request) {
+		resource := &Resource{Content: map[string]*Content{}}
+
+		for _, entry := range []struct {
+			name  string
+			media MediaType
+			value interface{}
+		}{
+			{"hostname", MediaTypeHMAPIString, "device-1"},
+			{"uptime", MediaTypeHMAPIInt64, int64(9007199254740993)},
+			{"config", MediaTypeTextPlain, config},
+			{"screenshot", MediaTypeOctetStream, bytes.NewReader(screenshot)},
+			{"thumbnail", MediaTypeOctetStream, []byte{1, 2, 3}},
+		} {
+			content, err := NewContent(entry.media, entry.value, "device/content/"+entry.name, 16)
+
+			assert.Nil(t.T(), err, entry.name)
+			resource.Content[entry.name] = content
+		}
+
+		writeTestResource(rw, resource)
+	}).Methods("GET")
+
+	ret.Mux.HandleFunc("/device/content/{name}", func(rw http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, mux.Vars(r)["name"])
+
+		switch mux.Vars(r)["name"] {
+		case "config":
+			WriteContent(rw, MediaTypeTextPlain, config)
+		case "screenshot":
+			WriteContent(rw, MediaTypeOctetStream, bytes.NewReader(screenshot))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}).Methods("GET")
+
+	resource, err := ret.Client.Resource("/device").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "", resource.Content["hostname"].Href)
+	assert.Equal(t.T(), "device/content/config", resource.Content["config"].Href)
+	assert.Equal(t.T(), "device/content/screenshot", resource.Content["screenshot"].Href)
+	assert.Equal(t.T(), "", resource.Content["thumbnail"].Href)
+
+	content, err := ret.Client.Resource("/device").Content("hostname").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "device-1", content.Value)
+
+	content, err = ret.Client.Resource("/device").Content("config").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), config, content.Value)
+
+	body, err := ret.Client.Resource("/device").Content("screenshot").Open(context.Background())
+
+	assert.Nil(t.T(), err)
+	b, _ := ioutil.ReadAll(body)
+	body.Close()
+	assert.Equal(t.T(), screenshot, b)
+
+	body, err = ret.Client.Resource("/device").Content("thumbnail").Open(context.Background())
+
+	assert.Nil(t.T(), err)
+	b, _ = ioutil.ReadAll(body)
+	body.Close()
+	assert.Equal(t.T(), []byte{1, 2, 3}, b)
+
+	body, err = ret.Client.Resource("/device").Content("uptime").Open(context.Background())
+
+	assert.Nil(t.T(), err)
+	b, _ = ioutil.ReadAll(body)
+	body.Close()
+	assert.Equal(t.T(), "9007199254740993", string(b))
+
+	assert.Equal(t.T(), []string{"config", "screenshot"}, fetched)
+
+	_, err = ret.Client.Resource("/device").Content("missing").Get(context.Background())
+
+	_, ok := err.(*ErrResourceNoSuchContent)
+	assert.True(t.T(), ok)
+}
+
+func (t *Test_ContentRequest_when_fetching) Test_out_of_line_scalar_decoded_by_type() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/device", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{
+			Content: map[string]*Content{
+				"uptime":  &Content{Type: MediaTypeHMAPIDuration, Href: "/device/uptime"},
+				"missing": &Content{Type: MediaTypeHMAPIInt, Href: "/device/missing"},
+			},
+		})
+	}).Methods("GET")
+
+	ret.Mux.HandleFunc("/device/uptime", func(rw http.ResponseWriter, r *http.Request) {
+		WriteContent(rw, MediaTypeHMAPIDuration, "90m")
+	}).Methods("GET")
+
+	content, err := ret.Client.Resource("/device").Content("uptime").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+
+	d, err := content.Duration()
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "1h30m0s", d.String())
+
+	_, err = ret.Client.Resource("/device").Content("missing").Get(context.Background())
+
+	_, ok := err.(*ErrUnexpectedHTTPResponseStatus)
+	assert.True(t.T(), ok)
+}
+
+func (t *Test_ContentRequest_when_fetching) Test_inline_and_out_of_line_values_share_type() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/device", func(rw http.ResponseWriter, r *http.Request) {
+		inline, _ := NewContent(MediaTypeHMAPIInt64, int64(42), "/device/inline", 64)
+		outofline, _ := NewContent(MediaTypeHMAPIInt64, int64(42), "/device/outofline", 0)
+
+		writeTestResource(rw, &Resource{
+			Content: map[string]*Content{
+				"inline":    inline,
+				"outofline": outofline,
+			},
+		})
+	}).Methods("GET")
+
+	ret.Mux.HandleFunc("/device/outofline", func(rw http.ResponseWriter, r *http.Request) {
+		WriteContent(rw, MediaTypeHMAPIInt64, int64(42))
+	}).Methods("GET")
+
+	inline, err := ret.Client.Resource("/device").Content("inline").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "", inline.Href)
+
+	outofline, err := ret.Client.Resource("/device").Content("outofline").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.NotEqual(t.T(), "", outofline.Href)
+	assert.Equal(t.T(), int64(42), inline.Value)
+	assert.Equal(t.T(), int64(42), outofline.Value)
+}
+
+func (t *Test_ContentRequest_when_fetching) Test_reader_without_href_inlined_once() {
+	content, err := NewContent(MediaTypeOctetStream, bytes.NewReader([]byte("thumbnail")), "", 0)
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "", content.Href)
+	assert.Equal(t.T(), []byte("thumbnail"), content.Value)
+}
+
+func TestRunContentTestSuites(t *testing.T) {
+	suite.Run(t, new(Test_ContentRequest_when_fetching))
+}
@@ -0,0 +1,436 @@
+package hmapi
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type FieldEncoder interface {
+	MediaType() MediaType
+	EncodeText(value interface{}) (string, error)
+	EncodeJSON(value interface{}) (interface{}, error)
+}
+
+type FieldStreamEncoder interface {
+	FieldEncoder
+	EncodeStream(w io.Writer, value interface{}) error
+}
+
+type FieldPartEncoder interface {
+	FieldEncoder
+	PartContentType() MediaType
+}
+
+type FieldValuesEncoder interface {
+	FieldEncoder
+	EncodeValues(name string, value interface{}, values url.Values) error
+}
+
+type FieldValuesDecoder interface {
+	DecodeValues(name string, values url.Values) (interface{}, bool, error)
+}
+
+type FieldDecoder interface {
+	DecodeText(text string) (interface{}, error)
+}
+
+var fieldEncoders = &fieldEncoderRegistry{
+	byMediaType: map[MediaType]FieldEncoder{},
+}
+
+func init() {
+	RegisterFieldEncoder(&stringFieldEncoder{mediaType: MediaTypeHMAPIString})
+	RegisterFieldEncoder(&stringFieldEncoder{mediaType: MediaTypeTextPlain})
+	RegisterFieldEncoder(&boolFieldEncoder{})
+	RegisterFieldEncoder(&intFieldEncoder{mediaType: MediaTypeHMAPIInt, bits: strconv.IntSize})
+	RegisterFieldEncoder(&intFieldEncoder{mediaType: MediaTypeHMAPIInt32, bits: 32})
+	RegisterFieldEncoder(&intFieldEncoder{mediaType: MediaTypeHMAPIInt64, bits: 64})
+	RegisterFieldEncoder(&uintFieldEncoder{mediaType: MediaTypeHMAPIUInt, bits: strconv.IntSize})
+	RegisterFieldEncoder(&uintFieldEncoder{mediaType: MediaTypeHMAPIUInt32, bits: 32})
+	RegisterFieldEncoder(&uintFieldEncoder{mediaType: MediaTypeHMAPIUInt64, bits: 64})
+	RegisterFieldEncoder(&floatFieldEncoder{mediaType: MediaTypeHMAPIFloat32, bits: 32})
+	RegisterFieldEncoder(&floatFieldEncoder{mediaType: MediaTypeHMAPIFloat64, bits: 64})
+	RegisterFieldEncoder(&octetStreamFieldEncoder{})
+	RegisterFieldEncoder(&timeFieldEncoder{mediaType: MediaTypeHMAPIDateTime, layout: time.RFC3339Nano})
+	RegisterFieldEncoder(&timeFieldEncoder{mediaType: MediaTypeHMAPIDate, layout: DateLayout})
+	RegisterFieldEncoder(&durationFieldEncoder{})
+	RegisterFieldEncoder(&jsonFieldEncoder{mediaType: MediaTypeHMAPIObject, kind: reflect.Map})
+	RegisterFieldEncoder(&jsonFieldEncoder{mediaType: MediaTypeHMAPIArray, kind: reflect.Slice})
+}
+
+func RegisterFieldEncoder(encoder FieldEncoder) {
+	fieldEncoders.register(encoder)
+}
+
+func LookupFieldEncoder(media MediaType) (FieldEncoder, bool) {
+	return fieldEncoders.lookup(media)
+}
+
+func ParseFieldText(media MediaType, text string) (interface{}, error) {
+	encoder, ok := LookupFieldEncoder(media)
+
+	if !ok {
+		return nil, &ErrUnsupportedMediaType{
+			MediaType: media,
+		}
+	}
+
+	decoder, ok := encoder.(FieldDecoder)
+
+	if !ok {
+		return nil, &ErrUnsupportedMediaType{
+			MediaType: media,
+		}
+	}
+
+	return decoder.DecodeText(text)
+}
+
+func typedFieldValue(encoder FieldEncoder, value interface{}) interface{} {
+	decoder, ok := encoder.(FieldDecoder)
+
+	if !ok {
+		return value
+	}
+
+	text, err := encoder.EncodeText(value)
+
+	if err != nil {
+		return value
+	}
+
+	typed, err := decoder.DecodeText(text)
+
+	if err != nil {
+		return value
+	}
+
+	return typed
+}
+
+type fieldEncoderRegistry struct {
+	mu          sync.RWMutex
+	byMediaType map[MediaType]FieldEncoder
+}
+
+func (t *fieldEncoderRegistry) register(encoder FieldEncoder) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.byMediaType[mediaTypeBase(encoder.MediaType())] = encoder
+}
+
+func (t *fieldEncoderRegistry) lookup(media MediaType) (FieldEncoder, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	encoder, ok := t.byMediaType[mediaTypeBase(media)]
+	return encoder, ok
+}
+
+type stringFieldEncoder struct {
+	mediaType MediaType
+}
+
+func (t *stringFieldEncoder) MediaType() MediaType {
+	return t.mediaType
+}
+
+func (t *stringFieldEncoder) EncodeText(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case fmt.Stringer:
+		return value.String(), nil
+	}
+
+	return "", fmt.Errorf("expected string value, got %T", value)
+}
+
+func (t *stringFieldEncoder) EncodeJSON(value interface{}) (interface{}, error) {
+	return t.EncodeText(value)
+}
+
+func (t *stringFieldEncoder) DecodeText(text string) (interface{}, error) {
+	return text, nil
+}
+
+type boolFieldEncoder struct{}
+
+func (t *boolFieldEncoder) MediaType() MediaType {
+	return MediaTypeHMAPIBoolean
+}
+
+func (t *boolFieldEncoder) EncodeText(value interface{}) (string, error) {
+	b, err := t.bool(value)
+	return strconv.FormatBool(b), err
+}
+
+func (t *boolFieldEncoder) EncodeJSON(value interface{}) (interface{}, error) {
+	return t.bool(value)
+}
+
+func (t *boolFieldEncoder) DecodeText(text string) (interface{}, error) {
+	return strconv.ParseBool(text)
+}
+
+func (t *boolFieldEncoder) bool(value interface{}) (bool, error) {
+	switch value := value.(type) {
+	case bool:
+		return value, nil
+	case string:
+		return strconv.ParseBool(value)
+	}
+
+	return false, fmt.Errorf("expected bool value, got %T", value)
+}
+
+type intFieldEncoder struct {
+	mediaType MediaType
+	bits      int
+}
+
+func (t *intFieldEncoder) MediaType() MediaType {
+	return t.mediaType
+}
+
+func (t *intFieldEncoder) EncodeText(value interface{}) (string, error) {
+	i, err := toInt64(value, t.bits)
+	return strconv.FormatInt(i, 10), err
+}
+
+func (t *intFieldEncoder) EncodeJSON(value interface{}) (interface{}, error) {
+	return toInt64(value, t.bits)
+}
+
+func (t *intFieldEncoder) DecodeText(text string) (interface{}, error) {
+	i, err := strconv.ParseInt(text, 10, t.bits)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.mediaType {
+	case MediaTypeHMAPIInt:
+		return int(i), nil
+	case MediaTypeHMAPIInt32:
+		return int32(i), nil
+	}
+
+	return i, nil
+}
+
+type uintFieldEncoder struct {
+	mediaType MediaType
+	bits      int
+}
+
+func (t *uintFieldEncoder) MediaType() MediaType {
+	return t.mediaType
+}
+
+func (t *uintFieldEncoder) EncodeText(value interface{}) (string, error) {
+	u, err := toUint64(value, t.bits)
+	return strconv.FormatUint(u, 10), err
+}
+
+func (t *uintFieldEncoder) EncodeJSON(value interface{}) (interface{}, error) {
+	return toUint64(value, t.bits)
+}
+
+func (t *uintFieldEncoder) DecodeText(text string) (interface{}, error) {
+	u, err := strconv.ParseUint(text, 10, t.bits)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.mediaType {
+	case MediaTypeHMAPIUInt:
+		return uint(u), nil
+	case MediaTypeHMAPIUInt32:
+		return uint32(u), nil
+	}
+
+	return u, nil
+}
+
+type floatFieldEncoder struct {
+	mediaType MediaType
+	bits      int
+}
+
+func (t *floatFieldEncoder) MediaType() MediaType {
+	return t.mediaType
+}
+
+func (t *floatFieldEncoder) EncodeText(value interface{}) (string, error) {
+	f, err := toFloat64(value, t.bits)
+	return strconv.FormatFloat(f, 'g', -1, t.bits), err
+}
+
+func (t *floatFieldEncoder) EncodeJSON(value interface{}) (interface{}, error) {
+	return toFloat64(value, t.bits)
+}
+
+func (t *floatFieldEncoder) DecodeText(text string) (interface{}, error) {
+	f, err := strconv.ParseFloat(text, t.bits)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if t.bits == 32 {
+		return float32(f), nil
+	}
+
+	return f, nil
+}
+
+type octetStreamFieldEncoder struct{}
+
+func (t *octetStreamFieldEncoder) MediaType() MediaType {
+	return MediaTypeOctetStream
+}
+
+func (t *octetStreamFieldEncoder) EncodeText(value interface{}) (string, error) {
+	b, err := t.bytes(value)
+	return string(b), err
+}
+
+func (t *octetStreamFieldEncoder) EncodeJSON(value interface{}) (interface{}, error) {
+	return t.bytes(value)
+}
+
+func (t *octetStreamFieldEncoder) EncodeStream(w io.Writer, value interface{}) error {
+	reader, ok := value.(io.Reader)
+
+	if !ok {
+		b, err := t.bytes(value)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(b)
+		return err
+	}
+
+	_, err := io.Copy(w, reader)
+	return err
+}
+
+func (t *octetStreamFieldEncoder) DecodeText(text string) (interface{}, error) {
+	return []byte(text), nil
+}
+
+func (t *octetStreamFieldEncoder) bytes(value interface{}) ([]byte, error) {
+	switch value := value.(type) {
+	case []byte:
+		return value, nil
+	case io.Reader:
+		return ioutil.ReadAll(value)
+	}
+
+	return nil, fmt.Errorf("expected io.Reader or []byte value, got %T", value)
+}
+
+func toInt64(value interface{}, bits int) (int64, error) {
+	var i int64
+
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i = v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("value %v overflows int%v", v.Uint(), bits)
+		}
+
+		i = int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		if v.Float() != math.Trunc(v.Float()) || v.Float() < math.MinInt64 || v.Float() >= math.MaxInt64 {
+			return 0, fmt.Errorf("value %v is not an integer", v.Float())
+		}
+
+		i = int64(v.Float())
+	case reflect.String:
+		parsed, err := strconv.ParseInt(v.String(), 10, bits)
+
+		if err != nil {
+			return 0, err
+		}
+
+		i = parsed
+	default:
+		return 0, fmt.Errorf("expected integer value, got %T", value)
+	}
+
+	if bits < 64 && (i < -1<<uint(bits-1) || i > 1<<uint(bits-1)-1) {
+		return 0, fmt.Errorf("value %v overflows int%v", i, bits)
+	}
+
+	return i, nil
+}
+
+func toUint64(value interface{}, bits int) (uint64, error) {
+	var u uint64
+
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return 0, fmt.Errorf("value %v is negative", v.Int())
+		}
+
+		u = uint64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u = v.Uint()
+	case reflect.Float32, reflect.Float64:
+		if v.Float() != math.Trunc(v.Float()) || v.Float() < 0 || v.Float() >= math.MaxUint64 {
+			return 0, fmt.Errorf("value %v is not an unsigned integer", v.Float())
+		}
+
+		u = uint64(v.Float())
+	case reflect.String:
+		parsed, err := strconv.ParseUint(v.String(), 10, bits)
+
+		if err != nil {
+			return 0, err
+		}
+
+		u = parsed
+	default:
+		return 0, fmt.Errorf("expected unsigned integer value, got %T", value)
+	}
+
+	if bits < 64 && u > 1<<uint(bits)-1 {
+		return 0, fmt.Errorf("value %v overflows uint%v", u, bits)
+	}
+
+	return u, nil
+}
+
+func toFloat64(value interface{}, bits int) (float64, error) {
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		if bits == 32 && math.Abs(v.Float()) > math.MaxFloat32 {
+			return 0, fmt.Errorf("value %v overflows float32", v.Float())
+		}
+
+		return v.Float(), nil
+	case reflect.String:
+		return strconv.ParseFloat(v.String(), bits)
+	}
+
+	return 0, fmt.Errorf("expected numeric value, got %T", value)
+}
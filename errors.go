@@ -1,19 +1,200 @@
 package hmapi
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"reflect"
+	"strings"
+	"syscall"
 )
 
+var (
+	ErrNotFound     = errors.New("hmapi: not found")
+	ErrUnauthorized = errors.New("hmapi: unauthorized")
+	ErrConflict     = errors.New("hmapi: conflict")
+	ErrTransient    = errors.New("hmapi: transient failure")
+)
+
+var redactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+const redactedValue = "REDACTED"
+
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrTransient) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var neterr net.Error
+
+	return errors.As(err, &neterr) && neterr.Timeout()
+}
+
+func statusCategory(status int) error {
+	switch status {
+	case http.StatusNotFound, http.StatusGone:
+		return ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return ErrConflict
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrTransient
+	}
+
+	return nil
+}
+
+func redactRequest(r *http.Request) *http.Request {
+	if r == nil {
+		return nil
+	}
+
+	redacted := r.Clone(r.Context())
+	redactHeader(redacted.Header)
+
+	if redacted.URL != nil && redacted.URL.User != nil {
+		redacted.URL.User = nil
+	}
+
+	return redacted
+}
+
+func redactResponse(resp *http.Response) *http.Response {
+	if resp == nil {
+		return nil
+	}
+
+	redacted := *resp
+	redacted.Header = resp.Header.Clone()
+	redacted.Request = redactRequest(resp.Request)
+	redactHeader(redacted.Header)
+
+	return &redacted
+}
+
+func redactHeader(header http.Header) {
+	for _, name := range redactedHeaders {
+		if _, ok := header[name]; ok {
+			header.Set(name, redactedValue)
+		}
+	}
+}
+
 type ErrResourceNoSuchLink struct {
 	Resource string
 	LinkName string
+	Name     string
 }
 
 func (t *ErrResourceNoSuchLink) Error() string {
+	if t.Name != "" {
+		return fmt.Sprintf("no link named '%v' with relation '%v' defined on resource '%v'", t.Name, t.LinkName, t.Resource)
+	}
+
 	return fmt.Sprintf("no such link with name '%v' defined on resource '%v'", t.LinkName, t.Resource)
 }
 
+func (t *ErrResourceNoSuchLink) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+type ErrResourceNoSuchEmbedded struct {
+	Resource string
+	Rel      string
+}
+
+func (t *ErrResourceNoSuchEmbedded) Error() string {
+	return fmt.Sprintf("no embedded resources with relation '%v' on resource '%v'", t.Rel, t.Resource)
+}
+
+func (t *ErrResourceNoSuchEmbedded) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+type ErrResourceNoSelf struct {
+	Resource *Resource
+}
+
+func (t *ErrResourceNoSelf) Error() string {
+	return "resource has no self link and cannot be addressed"
+}
+
+type ErrMethodNotAllowed struct {
+	Href    string
+	Method  Method
+	Allowed []Method
+}
+
+func (t *ErrMethodNotAllowed) Error() string {
+	return fmt.Sprintf("method '%v' is not allowed on '%v', allowed methods are %v", t.Method, t.Href, t.Allowed)
+}
+
+type ErrUnsupportedFormMethod struct {
+	FormName string
+	Method   Method
+}
+
+func (t *ErrUnsupportedFormMethod) Error() string {
+	return fmt.Sprintf("form '%v' uses method '%v' which cannot be used to submit a form", t.FormName, t.Method)
+}
+
+type ErrResourceNoSuchContent struct {
+	Resource    string
+	ContentName string
+}
+
+func (t *ErrResourceNoSuchContent) Error() string {
+	return fmt.Sprintf("no such content with name '%v' defined on resource '%v'", t.ContentName, t.Resource)
+}
+
+func (t *ErrResourceNoSuchContent) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+type ErrMissingETag struct {
+	Resource string
+}
+
+func (t *ErrMissingETag) Error() string {
+	return fmt.Sprintf("resource '%v' did not return an etag to submit as If-Match", t.Resource)
+}
+
+type ErrPreconditionFailed struct {
+	ETag           string
+	Resource       *Resource
+	RefreshError   error
+	ClientRequest  *http.Request
+	ClientResponse *http.Response
+}
+
+func (t *ErrPreconditionFailed) Error() string {
+	if t.RefreshError != nil {
+		return fmt.Sprintf("precondition failed, resource no longer matches etag %v and could not be refreshed: %v", t.ETag, t.RefreshError)
+	}
+
+	return fmt.Sprintf("precondition failed, resource no longer matches etag %v", t.ETag)
+}
+
+func (t *ErrPreconditionFailed) Unwrap() error {
+	return ErrConflict
+}
+
 type ErrResourceNoSuchForm struct {
 	Resource string
 	FormName string
@@ -23,6 +204,10 @@ func (t *ErrResourceNoSuchForm) Error() string {
 	return fmt.Sprintf("no such form with name '%v' defined on resource '%v'", t.FormName, t.Resource)
 }
 
+func (t *ErrResourceNoSuchForm) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 type ErrUnsupportedMediaType struct {
 	MediaType MediaType
 }
@@ -31,17 +216,53 @@ func (t *ErrUnsupportedMediaType) Error() string {
 	return fmt.Sprintf("media type '%v' is not supported", t.MediaType.String())
 }
 
+type ErrUnsupportedStructField struct {
+	FieldName string
+	Type      reflect.Type
+}
+
+func (t *ErrUnsupportedStructField) Error() string {
+	if t.FieldName == "" {
+		return fmt.Sprintf("type '%v' cannot be bound to a form, expected a pointer to a struct", t.Type)
+	}
+
+	return fmt.Sprintf("struct field '%v' of type '%v' cannot be bound to a form field", t.FieldName, t.Type)
+}
+
+type ErrInvalidFieldValue struct {
+	FieldName string
+	MediaType MediaType
+	Err       error
+}
+
+func (t *ErrInvalidFieldValue) Error() string {
+	return fmt.Sprintf("invalid value for field '%v' of type '%v': %v", t.FieldName, t.MediaType.String(), t.Err)
+}
+
+func (t *ErrInvalidFieldValue) Unwrap() error {
+	return t.Err
+}
+
 type ErrUnexpectedHTTPResponseStatus struct {
 	ExpectedStatus int
 	ActualStatus   int
+	Body           []byte
 	ClientRequest  *http.Request
 	ClientResponse *http.Response
 }
 
 func (t *ErrUnexpectedHTTPResponseStatus) Error() string {
+	if snippet := strings.TrimSpace(string(t.Body)); snippet != "" {
+		return fmt.Sprintf("expected status %v received %v: %v", t.ExpectedStatus, t.ActualStatus, snippet)
+	}
+
 	return fmt.Sprintf("expected status %v received %v", t.ExpectedStatus, t.ActualStatus)
 }
 
+func (t *ErrUnexpectedHTTPResponseStatus) Unwrap() error {
+	return statusCategory(t.ActualStatus)
+}
+
 type ErrResourceUnmarshalFailure struct {
 	UnmarshalError error
 	ClientRequest  *http.Request
@@ -51,3 +272,98 @@ type ErrResourceUnmarshalFailure struct {
 func (t *ErrResourceUnmarshalFailure) Error() string {
 	return t.UnmarshalError.Error()
 }
+
+func (t *ErrResourceUnmarshalFailure) Unwrap() error {
+	return t.UnmarshalError
+}
+
+type ErrOperationNoStatus struct {
+	ClientRequest  *http.Request
+	ClientResponse *http.Response
+}
+
+func (t *ErrOperationNoStatus) Error() string {
+	return "accepted operation did not provide a status location"
+}
+
+type ErrOperationFailed struct {
+	State  OperationState
+	Status *Resource
+}
+
+func (t *ErrOperationFailed) Error() string {
+	return fmt.Sprintf("operation finished with state '%v'", t.State.String())
+}
+
+type ErrUnexpectedContentType struct {
+	Expected       []MediaType
+	Actual         MediaType
+	ClientRequest  *http.Request
+	ClientResponse *http.Response
+}
+
+func (t *ErrUnexpectedContentType) Error() string {
+	expected := make([]string, len(t.Expected))
+
+	for i, media := range t.Expected {
+		expected[i] = media.String()
+	}
+
+	return fmt.Sprintf("expected content type %v received '%v'", strings.Join(expected, " or "), t.Actual.String())
+}
+
+type ErrCodecMalformed struct {
+	MediaType MediaType
+	Reason    string
+}
+
+func (t *ErrCodecMalformed) Error() string {
+	return fmt.Sprintf("malformed '%v' document: %v", t.MediaType.String(), t.Reason)
+}
+
+type ErrContentType struct {
+	Type  MediaType
+	Value interface{}
+	Want  string
+}
+
+func (t *ErrContentType) Error() string {
+	return fmt.Sprintf("content of type '%v' (%T) cannot be read as %v", t.Type.String(), t.Value, t.Want)
+}
+
+type ErrContentDecode struct {
+	ContentErrors []*FieldError
+}
+
+func (t *ErrContentDecode) Error() string {
+	reasons := make([]string, len(t.ContentErrors))
+
+	for i, contenterr := range t.ContentErrors {
+		reasons[i] = fmt.Sprintf("%v %v", contenterr.Name, contenterr.Reason)
+	}
+
+	return "content decode failed: " + strings.Join(reasons, "; ")
+}
+
+type ErrURITemplate struct {
+	Template string
+	Reason   string
+}
+
+func (t *ErrURITemplate) Error() string {
+	return fmt.Sprintf("invalid uri template '%v': %v", t.Template, t.Reason)
+}
+
+type ErrValidation struct {
+	FieldErrors []*FieldError
+}
+
+func (t *ErrValidation) Error() string {
+	reasons := make([]string, len(t.FieldErrors))
+
+	for i, fielderr := range t.FieldErrors {
+		reasons[i] = fmt.Sprintf("%v %v", fielderr.Name, fielderr.Reason)
+	}
+
+	return "form validation failed: " + strings.Join(reasons, "; ")
+}
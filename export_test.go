@@ -0,0 +1,5 @@
+package hmapi
+
+// NewTestServerAndClient exposes the shared test server helper to the
+// external hmapi_test package.
+var NewTestServerAndClient = newTestServerAndClient
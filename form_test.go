@@ -3,14 +3,18 @@ package hmapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"mime/multipart"
 	"net"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"net/http"
 
@@ -59,6 +63,7 @@ func (t *Test_FormRequest_when_calling_submit) Test_submission_canceled_successf
 			return
 		}
 
+		rw.Header().Set("Content-Type", MediaTypeHMAPIResource.String())
 		rw.WriteHeader(http.StatusOK)
 		rw.Write(b)
 	}).Methods("GET")
@@ -119,6 +124,7 @@ func (t *Test_FormRequest_when_calling_submit) Test_multipart_form_successfully_
 			return
 		}
 
+		rw.Header().Set("Content-Type", MediaTypeHMAPIResource.String())
 		rw.WriteHeader(http.StatusOK)
 		rw.Write(b)
 	}).Methods("GET")
@@ -134,6 +140,879 @@ func (t *Test_FormRequest_when_calling_submit) Test_multipart_form_successfully_
 	assert.Equal(t.T(), "response", string(respbody))
 }
 
+func (t *Test_FormRequest_when_calling_submit) Test_async_submission_completes_successfully() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(4096); err != nil || r.Form.Get("foo") != "test" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte("response"))
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/resource", t.serveTestForm(POST, "/resource/test")).Methods("GET")
+
+	submission := ret.Client.Resource("/resource").Form("test").AddFieldAsString("foo", "test").SubmitAsync(context.Background())
+
+	select {
+	case <-submission.Done():
+	case <-time.After(5 * time.Second):
+		assert.FailNow(t.T(), "async submission did not complete")
+	}
+
+	assert.Nil(t.T(), submission.Err())
+	assert.NotNil(t.T(), submission.Response())
+	assert.Equal(t.T(), http.StatusOK, submission.Response().StatusCode)
+	assert.True(t.T(), submission.BytesWritten() > 0)
+
+	respbody, _ := ioutil.ReadAll(submission.Response().Body)
+
+	assert.Equal(t.T(), "response", string(respbody))
+	assert.Nil(t.T(), submission.Response().Request.Context().Err())
+
+	submission.Response().Body.Close()
+
+	assert.Equal(t.T(), context.Canceled, submission.Response().Request.Context().Err())
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_async_submission_canceled_successfully() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	chreceived := make(chan struct{})
+	chrelease := make(chan struct{})
+	defer close(chrelease)
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		close(chreceived)
+		<-chrelease
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/resource", t.serveTestForm(POST, "/resource/test")).Methods("GET")
+
+	submission := ret.Client.Resource("/resource").Form("test").AddFieldAsString("foo", "test").SubmitAsync(context.Background())
+
+	<-chreceived
+	submission.Cancel()
+
+	select {
+	case <-submission.Done():
+	case <-time.After(5 * time.Second):
+		assert.FailNow(t.T(), "async submission was not canceled")
+	}
+
+	assert.Nil(t.T(), submission.Response())
+	assert.NotNil(t.T(), submission.Err())
+	assert.True(t.T(), strings.Contains(submission.Err().Error(), "context canceled"))
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_response_resource_follows_created_location() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Location", "/devices/1")
+		rw.WriteHeader(http.StatusCreated)
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/devices/1", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{
+			Content: map[string]*Content{
+				"hostname": &Content{Type: MediaTypeHMAPIString, Value: "device1"},
+			},
+		})
+	}).Methods("GET")
+
+	ret.Mux.HandleFunc("/resource", t.serveTestForm(POST, "/resource/test")).Methods("GET")
+
+	resp, err := ret.Client.Resource("/resource").Form("test").AddFieldAsString("foo", "test").Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+
+	resource, err := resp.Resource(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.NotNil(t.T(), resource)
+	assert.Equal(t.T(), "device1", resource.Content["hostname"].Value)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_response_resource_decodes_returned_body() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{
+			Content: map[string]*Content{
+				"result": &Content{Type: MediaTypeHMAPIString, Value: "ok"},
+			},
+		})
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/resource", t.serveTestForm(POST, "/resource/test")).Methods("GET")
+
+	resp, err := ret.Client.Resource("/resource").Form("test").AddFieldAsString("foo", "test").Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+
+	resource, err := resp.Resource(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "ok", resource.Content["result"].Value)
+	assert.NotNil(t.T(), resource.Forms)
+	assert.NotNil(t.T(), resource.Links)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_response_decode_json_returns_error_when_non_2xx() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`{"ok":false}`))
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/resource", t.serveTestForm(POST, "/resource/test")).Methods("GET")
+
+	resp, err := ret.Client.Resource("/resource").Form("test").AddFieldAsString("foo", "test").Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+
+	var v struct {
+		OK bool `json:"ok"`
+	}
+
+	err = resp.DecodeJSON(&v)
+
+	assert.NotNil(t.T(), err)
+
+	e, ok := err.(*ErrUnexpectedHTTPResponseStatus)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), http.StatusBadRequest, e.ActualStatus)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_problem_response_maps_field_errors() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		WriteProblem(rw, &ErrProblem{
+			Type:   "https://example.com/probs/validation",
+			Title:  "validation failed",
+			Status: http.StatusUnprocessableEntity,
+			Detail: "one or more fields are invalid",
+			InvalidParams: []*ProblemParam{
+				&ProblemParam{Name: "foo", Reason: "must not be test"},
+				&ProblemParam{Name: "bar", Reason: "unknown field"},
+			},
+		})
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/resource", t.serveTestForm(POST, "/resource/test")).Methods("GET")
+
+	resp, err := ret.Client.Resource("/resource").Form("test").AddFieldAsString("foo", "test").Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+
+	e, ok := resp.Err().(*ErrProblem)
+
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), "validation failed: one or more fields are invalid", e.Error())
+	assert.Equal(t.T(), http.StatusUnprocessableEntity, e.Status)
+	assert.Equal(t.T(), 2, len(e.FieldErrors))
+	assert.Equal(t.T(), "foo", e.FieldErrors[0].Name)
+	assert.Equal(t.T(), "must not be test", e.FieldErrors[0].Reason)
+	assert.NotNil(t.T(), e.FieldErrors[0].Field)
+	assert.Equal(t.T(), MediaTypeHMAPIString, e.FieldErrors[0].Field.Type)
+	assert.Nil(t.T(), e.FieldErrors[1].Field)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_registered_field_encoder_used_for_vendor_type() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	vendortype := MediaType("application/vnd.example.Upper")
+	RegisterFieldEncoder(&upperFieldEncoder{mediaType: vendortype})
+
+	var received string
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(4096)
+		received = r.Form.Get("name")
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/resource", t.serveForm(&Form{
+		Action:  "/resource/test",
+		Method:  POST,
+		Enctype: MediaTypeMultipartFormData,
+		Fields: []*FormField{
+			&FormField{Name: "name", Type: vendortype},
+		},
+	})).Methods("GET")
+
+	resp, err := ret.Client.Resource("/resource").Form("test").AddField("name", vendortype, "device").Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t.T(), "DEVICE", received)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_unregistered_field_type_returns_error() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/resource", t.serveTestForm(POST, "/resource/test")).Methods("GET")
+
+	resp, err := ret.Client.Resource("/resource").Form("test").AddField("foo", "application/vnd.example.Unknown", "x").Submit(context.Background())
+
+	assert.Nil(t.T(), resp)
+
+	e, ok := err.(*ErrUnsupportedMediaType)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), MediaType("application/vnd.example.Unknown"), e.MediaType)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_urlencoded_form_successfully_submitted() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	var form url.Values
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/resource", t.serveForm(&Form{
+		Action:  "/resource/test",
+		Method:  POST,
+		Enctype: MediaTypeFormURLEncoded,
+	})).Methods("GET")
+
+	_, err := ret.Client.Resource("/resource").Form("test").
+		AddFieldAsString("foo", "a b").
+		AddFieldAsInt("count", 3).
+		AddField("ratio", MediaTypeHMAPIFloat64, 0.5).
+		Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "a b", form.Get("foo"))
+	assert.Equal(t.T(), "3", form.Get("count"))
+	assert.Equal(t.T(), "0.5", form.Get("ratio"))
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_json_form_successfully_submitted() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	var body map[string]interface{}
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/resource", t.serveForm(&Form{
+		Action:  "/resource/test",
+		Method:  POST,
+		Enctype: MediaTypeJSON,
+		Fields: []*FormField{
+			&FormField{Name: "tags", Type: MediaTypeHMAPIString, Multiple: true},
+		},
+	})).Methods("GET")
+
+	_, err := ret.Client.Resource("/resource").Form("test").
+		AddFieldAsString("foo", "bar").
+		AddFieldAsBool("enabled", true).
+		AddFieldAsInt("count", 3).
+		AddFieldAsString("tags", "a").
+		Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "bar", body["foo"])
+	assert.Equal(t.T(), true, body["enabled"])
+	assert.Equal(t.T(), float64(3), body["count"])
+	assert.Equal(t.T(), []interface{}{"a"}, body["tags"])
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_invalid_field_value_returns_error() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/resource", t.serveTestForm(POST, "/resource/test")).Methods("GET")
+
+	_, err := ret.Client.Resource("/resource").Form("test").AddField("foo", MediaTypeHMAPIInt32, int64(1)<<40).Submit(context.Background())
+
+	e, ok := err.(*ErrInvalidFieldValue)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), "foo", e.FieldName)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_temporal_fields_successfully_submitted() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	at := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+
+	var (
+		received      time.Time
+		receivedDate  time.Time
+		receivedDelay time.Duration
+		parseErrs     []error
+	)
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		var err error
+
+		if received, err = FormValueTime(r, "at"); err != nil {
+			parseErrs = append(parseErrs, err)
+		}
+
+		if receivedDate, err = FormValueDate(r, "on"); err != nil {
+			parseErrs = append(parseErrs, err)
+		}
+
+		if receivedDelay, err = FormValueDuration(r, "delay"); err != nil {
+			parseErrs = append(parseErrs, err)
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/resource", t.serveForm(&Form{
+		Action:  "/resource/test",
+		Method:  POST,
+		Enctype: MediaTypeMultipartFormData,
+		Fields: []*FormField{
+			&FormField{Name: "at", Type: MediaTypeHMAPIDateTime, Required: true},
+			&FormField{Name: "on", Type: MediaTypeHMAPIDate},
+			&FormField{Name: "delay", Type: MediaTypeHMAPIDuration},
+		},
+	})).Methods("GET")
+
+	_, err := ret.Client.Resource("/resource").Form("test").
+		AddFieldAsTime("at", at).
+		AddField("on", MediaTypeHMAPIDate, at).
+		AddFieldAsDuration("delay", 90*time.Second).
+		Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), parseErrs)
+	assert.True(t.T(), at.Equal(received))
+	assert.Equal(t.T(), "2026-10-19", receivedDate.Format(DateLayout))
+	assert.Equal(t.T(), 90*time.Second, receivedDelay)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_structured_fields_encoded_for_each_enctype() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	type network struct {
+		Address string   `json:"address"`
+		DNS     []string `json:"dns"`
+	}
+
+	config := &network{Address: "10.0.0.2", DNS: []string{"1.1.1.1", "8.8.8.8"}}
+	env := []string{"A=1", "B=2"}
+
+	var (
+		contentType   string
+		multipartbody string
+		urlencoded    url.Values
+		jsonbody      map[string]interface{}
+		networkvalue  interface{}
+		envvalue      interface{}
+		networkerr    error
+		enverr        error
+	)
+
+	ret.Mux.HandleFunc("/multipart", func(rw http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+
+		for err == nil {
+			var part *multipart.Part
+
+			if part, err = reader.NextPart(); err == nil && part.FormName() == "network" {
+				contentType = part.Header.Get("Content-Type")
+				b, _ := ioutil.ReadAll(part)
+				multipartbody = string(b)
+			}
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/urlencoded", func(rw http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		urlencoded = r.PostForm
+		networkvalue, networkerr = FormValue(r, "network", MediaTypeHMAPIObject)
+		envvalue, enverr = FormValue(r, "env", MediaTypeHMAPIArray)
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/json", func(rw http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&jsonbody)
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	for _, enctype := range []MediaType{MediaTypeMultipartFormData, MediaTypeFormURLEncoded, MediaTypeJSON} {
+		action := map[MediaType]string{
+			MediaTypeMultipartFormData: "/multipart",
+			MediaTypeFormURLEncoded:    "/urlencoded",
+			MediaTypeJSON:              "/json",
+		}[enctype]
+
+		ret.Mux.HandleFunc("/resource"+action, t.serveForm(&Form{
+			Action:  action,
+			Method:  POST,
+			Enctype: enctype,
+			Fields: []*FormField{
+				&FormField{
+					Name: "network",
+					Type: MediaTypeHMAPIObject,
+					Fields: []*FormField{
+						&FormField{Name: "address", Type: MediaTypeHMAPIString, Required: true},
+						&FormField{Name: "dns", Type: MediaTypeHMAPIString, Multiple: true},
+					},
+				},
+				&FormField{Name: "env", Type: MediaTypeHMAPIArray},
+			},
+		})).Methods("GET")
+
+		_, err := ret.Client.Resource("/resource"+action).Form("test").
+			AddFieldAsJSON("network", config).
+			AddFieldAsJSON("env", env).
+			Submit(context.Background())
+
+		assert.Nil(t.T(), err, enctype.String())
+	}
+
+	assert.Equal(t.T(), "application/json", contentType)
+	assert.Equal(t.T(), `{"address":"10.0.0.2","dns":["1.1.1.1","8.8.8.8"]}`, multipartbody)
+
+	assert.Equal(t.T(), "10.0.0.2", urlencoded.Get("network[address]"))
+	assert.Equal(t.T(), "8.8.8.8", urlencoded.Get("network[dns][1]"))
+	assert.Equal(t.T(), "B=2", urlencoded.Get("env[1]"))
+	assert.Nil(t.T(), networkerr)
+	assert.Nil(t.T(), enverr)
+	assert.Equal(t.T(), map[string]interface{}{
+		"address": "10.0.0.2",
+		"dns":     []interface{}{"1.1.1.1", "8.8.8.8"},
+	}, networkvalue)
+	assert.Equal(t.T(), []interface{}{"A=1", "B=2"}, envvalue)
+
+	assert.Equal(t.T(), map[string]interface{}{
+		"address": "10.0.0.2",
+		"dns":     []interface{}{"1.1.1.1", "8.8.8.8"},
+	}, jsonbody["network"])
+	assert.Equal(t.T(), []interface{}{"A=1", "B=2"}, jsonbody["env"])
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_constraint_violations_rejected_before_submission() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	var submitted bool
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		submitted = true
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	form := &Form{
+		Action:  "/resource/test",
+		Method:  POST,
+		Enctype: MediaTypeMultipartFormData,
+	}
+
+	form.Field("mode", MediaTypeHMAPIString).Require().
+		WithOption("dhcp", "DHCP").
+		WithOption("static", "Static").
+		WithOption("off", "Disabled")
+	form.Field("port", MediaTypeHMAPIInt).WithRange(1, 65535)
+	form.Field("hostname", MediaTypeHMAPIString).WithLength(1, 63).WithPattern("[a-z0-9-]+")
+	form.Field("serial", MediaTypeHMAPIString).Require()
+
+	ret.Mux.HandleFunc("/resource", t.serveForm(form)).Methods("GET")
+
+	resp, err := ret.Client.Resource("/resource").Form("test").
+		AddFieldAsString("mode", "manual").
+		AddFieldAsInt("port", 70000).
+		AddFieldAsString("hostname", "Device_1").
+		Submit(context.Background())
+
+	assert.Nil(t.T(), resp)
+	assert.False(t.T(), submitted)
+
+	e, ok := err.(*ErrValidation)
+	assert.True(t.T(), ok)
+
+	names := []string{}
+
+	for _, fielderr := range e.FieldErrors {
+		names = append(names, fielderr.Name)
+		assert.NotNil(t.T(), fielderr.Field)
+	}
+
+	assert.Equal(t.T(), []string{"mode", "port", "hostname", "serial"}, names)
+
+	err = ret.Client.Resource("/resource").Form("test").
+		AddFieldAsString("mode", "static").
+		AddFieldAsInt("port", 8080).
+		AddFieldAsString("hostname", "device-1").
+		AddFieldAsString("serial", "A1").
+		Validate(context.Background())
+
+	assert.Nil(t.T(), err)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_use_defaults_prefills_published_values() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	var body map[string]interface{}
+
+	ret.Mux.HandleFunc("/resource/settings", func(rw http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/resource", t.serveForm(&Form{
+		Action:  "/resource/settings",
+		Method:  POST,
+		Enctype: MediaTypeJSON,
+		Fields: []*FormField{
+			&FormField{Name: "hostname", Type: MediaTypeHMAPIString, Required: true, Value: "device-1"},
+			&FormField{Name: "port", Type: MediaTypeHMAPIInt, Value: 8080},
+			&FormField{Name: "dhcp", Type: MediaTypeHMAPIBoolean, Value: true},
+			&FormField{Name: "dns", Type: MediaTypeHMAPIString, Multiple: true, Value: []string{"1.1.1.1", "8.8.8.8"}},
+			&FormField{Name: "mtu", Type: MediaTypeHMAPIInt, Default: 1500},
+			&FormField{Name: "mode", Type: MediaTypeHMAPIString, Value: "static", Default: "dhcp"},
+			&FormField{Name: "firmware", Type: MediaTypeOctetStream},
+		},
+	})).Methods("GET")
+
+	_, err := ret.Client.Resource("/resource").Form("test").
+		UseDefaults().
+		AddFieldAsInt("port", 9090).
+		Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), map[string]interface{}{
+		"hostname": "device-1",
+		"port":     float64(9090),
+		"dhcp":     true,
+		"dns":      []interface{}{"1.1.1.1", "8.8.8.8"},
+		"mtu":      float64(1500),
+		"mode":     "static",
+	}, body)
+
+	_, err = ret.Client.Resource("/resource").Form("test").
+		AddFieldAsInt("port", 9090).
+		Submit(context.Background())
+
+	assert.NotNil(t.T(), err)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_templated_action_filled_from_fields() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	var requested string
+
+	ret.Mux.HandleFunc("/devices/{id}/reboot", func(rw http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	form := &Form{
+		Action:    "/devices/{id}/reboot{?delay}",
+		Templated: true,
+		Method:    POST,
+		Enctype:   MediaTypeFormURLEncoded,
+	}
+
+	form.Field("id", MediaTypeHMAPIInt).Require()
+	form.Field("delay", MediaTypeHMAPIDuration)
+
+	ret.Mux.HandleFunc("/resource", t.serveForm(form)).Methods("GET")
+
+	_, err := ret.Client.Resource("/resource").Form("test").
+		AddFieldAsInt("id", 42).
+		AddFieldAsDuration("delay", 5*time.Second).
+		Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "/devices/42/reboot?delay=PT5S", requested)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_propagates_query_and_headers() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	requests := []string{}
+
+	ret.Mux.HandleFunc("/resource", func(rw http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("X-Feature"))
+		t.serveTestForm(POST, "/resource/test")(rw, r)
+	}).Methods("GET")
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("X-Feature"))
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	_, err := ret.Client.Resource("/resource").Form("test").
+		WithQuery(url.Values{"dryRun": []string{"true"}}).
+		WithHeader("X-Feature", "beta").
+		AddFieldAsString("foo", "bar").
+		Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), []string{
+		"GET /resource?dryRun=true beta",
+		"POST /resource/test?dryRun=true beta",
+	}, requests)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_get_form_fields_sent_in_query() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	var requested, contentType string
+	var body []byte
+
+	ret.Mux.HandleFunc("/resource/search", func(rw http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		contentType = r.Header.Get("Content-Type")
+		body, _ = ioutil.ReadAll(r.Body)
+		rw.WriteHeader(http.StatusOK)
+	}).Methods("GET")
+
+	form := &Form{Action: "/resource/search", Method: GET, Enctype: MediaTypeMultipartFormData}
+	form.Field("q", MediaTypeHMAPIString).Require()
+	form.Field("limit", MediaTypeHMAPIInt)
+
+	ret.Mux.HandleFunc("/resource", t.serveForm(form)).Methods("GET")
+
+	resp, err := ret.Client.Resource("/resource").Form("test").
+		AddFieldAsString("q", "eth0 up").
+		AddFieldAsInt("limit", 10).
+		Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), resp.Err())
+	assert.Equal(t.T(), "/resource/search?limit=10&q=eth0+up", requested)
+	assert.Equal(t.T(), "", contentType)
+	assert.Equal(t.T(), 0, len(body))
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_empty_delete_form_sends_no_body() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	var contentType string
+	var contentLength int64 = -1
+
+	ret.Mux.HandleFunc("/resource/test", func(rw http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		contentLength = r.ContentLength
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("DELETE")
+
+	ret.Mux.HandleFunc("/resource", t.serveForm(&Form{
+		Action:  "/resource/test",
+		Method:  DELETE,
+		Enctype: MediaTypeMultipartFormData,
+	})).Methods("GET")
+
+	resp, err := ret.Client.Resource("/resource").Form("test").Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t.T(), "", contentType)
+	assert.Equal(t.T(), int64(0), contentLength)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_connect_and_trace_forms_rejected() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	for _, m := range []Method{CONNECT, TRACE} {
+		ret.Mux.HandleFunc("/resource/"+m.String(), t.serveForm(&Form{
+			Action: "/resource/test",
+			Method: m,
+		})).Methods("GET")
+
+		_, err := ret.Client.Resource("/resource/" + m.String()).Form("test").Submit(context.Background())
+
+		e, ok := err.(*ErrUnsupportedFormMethod)
+		assert.True(t.T(), ok, m.String())
+		assert.Equal(t.T(), m, e.Method)
+	}
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_if_match_detects_concurrent_update() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	version := 1
+	hostname := "device-1"
+
+	etag := func() string {
+		return fmt.Sprintf(`"v%v"`, version)
+	}
+
+	ret.Mux.HandleFunc("/device/config", func(rw http.ResponseWriter, r *http.Request) {
+		if !CheckPreconditions(rw, r, etag()) {
+			return
+		}
+
+		resource := &Resource{
+			ETag: etag(),
+			Forms: map[string]*Form{
+				"update": &Form{Action: "/device/config", Method: PUT, Enctype: MediaTypeFormURLEncoded},
+			},
+		}
+		resource.Forms["update"].Field("hostname", MediaTypeHMAPIString).Require()
+
+		WriteResource(rw, r, resource)
+	}).Methods("GET")
+
+	ret.Mux.HandleFunc("/device/config", func(rw http.ResponseWriter, r *http.Request) {
+		if !CheckPreconditions(rw, r, etag()) {
+			return
+		}
+
+		hostname = r.FormValue("hostname")
+		version++
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("PUT")
+
+	stale, err := ret.Client.Resource("/device/config").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), `"v1"`, stale.ETag)
+
+	resp, err := ret.Client.Resource("/device/config").Form("update").
+		IfMatch().
+		AddFieldAsString("hostname", "device-2").
+		Submit(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), http.StatusNoContent, resp.StatusCode)
+
+	stalereq, err := ret.Client.FromResource(stale)
+
+	assert.Nil(t.T(), err)
+
+	_, err = stalereq.Form("update").
+		IfMatch().
+		AddFieldAsString("hostname", "device-3").
+		Submit(context.Background())
+
+	assert.True(t.T(), errors.Is(err, ErrConflict))
+	assert.Equal(t.T(), "device-2", hostname)
+
+	e, ok := err.(*ErrPreconditionFailed)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), `"v1"`, e.ETag)
+	assert.Equal(t.T(), `"v2"`, e.Resource.ETag)
+
+	request, _ := http.NewRequest(GET.String(), ret.Server.URL+"/device/config", nil)
+	request.Header.Set("If-None-Match", `W/"v2"`)
+
+	notmodified, err := http.DefaultClient.Do(request)
+
+	assert.Nil(t.T(), err)
+	notmodified.Body.Close()
+	assert.Equal(t.T(), http.StatusNotModified, notmodified.StatusCode)
+}
+
+func (t *Test_FormRequest_when_calling_submit) Test_if_match_reports_failed_refresh() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	fetches := 0
+
+	ret.Mux.HandleFunc("/device/config", func(rw http.ResponseWriter, r *http.Request) {
+		if fetches++; fetches > 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		resource := &Resource{
+			ETag: `"v1"`,
+			Forms: map[string]*Form{
+				"update": &Form{Action: "/device/config", Method: PUT, Enctype: MediaTypeFormURLEncoded},
+			},
+		}
+
+		WriteResource(rw, r, resource)
+	}).Methods("GET")
+
+	ret.Mux.HandleFunc("/device/config", func(rw http.ResponseWriter, r *http.Request) {
+		CheckPreconditions(rw, r, `"v2"`)
+	}).Methods("PUT")
+
+	_, err := ret.Client.Resource("/device/config").Form("update").
+		IfMatch().
+		AddFieldAsString("hostname", "device-2").
+		Submit(context.Background())
+
+	e, ok := err.(*ErrPreconditionFailed)
+	assert.True(t.T(), ok)
+	assert.True(t.T(), errors.Is(err, ErrConflict))
+	assert.Nil(t.T(), e.Resource)
+	assert.True(t.T(), errors.Is(e.RefreshError, ErrTransient))
+	assert.True(t.T(), strings.Contains(err.Error(), "could not be refreshed"))
+}
+
+func (t *Test_FormRequest_when_calling_submit) serveTestForm(method Method, action string) http.HandlerFunc {
+	return t.serveForm(&Form{
+		Action:  action,
+		Method:  method,
+		Enctype: MediaTypeMultipartFormData,
+		Type:    "none",
+		Fields: []*FormField{
+			&FormField{
+				Name:     "foo",
+				Type:     MediaTypeHMAPIString,
+				Required: true,
+			},
+		},
+	})
+}
+
+func (t *Test_FormRequest_when_calling_submit) serveForm(form *Form) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		b, err := json.Marshal(&Resource{
+			Forms: map[string]*Form{
+				"test": form,
+			},
+		})
+
+		if err != nil {
+			assert.FailNow(t.T(), "error marshal json", err)
+			return
+		}
+
+		rw.Header().Set("Content-Type", MediaTypeHMAPIResource.String())
+		rw.WriteHeader(http.StatusOK)
+		rw.Write(b)
+	}
+}
+
 func (t *Test_FormRequest_when_calling_submit) getTestServerAndClient() (ret struct {
 	Mux    *mux.Router
 	Host   string
@@ -167,6 +1046,22 @@ func (t *Test_FormRequest_when_calling_submit) getTestServerAndClient() (ret str
 	return
 }
 
+type upperFieldEncoder struct {
+	mediaType MediaType
+}
+
+func (t *upperFieldEncoder) MediaType() MediaType {
+	return t.mediaType
+}
+
+func (t *upperFieldEncoder) EncodeText(value interface{}) (string, error) {
+	return strings.ToUpper(value.(string)), nil
+}
+
+func (t *upperFieldEncoder) EncodeJSON(value interface{}) (interface{}, error) {
+	return t.EncodeText(value)
+}
+
 func TestRunFormTestSuites(t *testing.T) {
 	suite.Run(t, new(Test_FormRequest_when_calling_submit))
 }
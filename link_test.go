@@ -0,0 +1,273 @@
+package hmapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type Test_LinkRequest_when_calling_get struct {
+	suite.Suite
+}
+
+func (t *Test_LinkRequest_when_calling_get) Test_sends_link_type_as_accept() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	var accept string
+
+	ret.Mux.HandleFunc("/resource", t.serveLink(&Link{Href: "/logs", Type: MediaTypeTextPlain}))
+	ret.Mux.HandleFunc("/logs", func(rw http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		rw.Write([]byte("log line"))
+	})
+
+	resp, err := ret.Client.Resource("/resource").Link("test").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), MediaTypeTextPlain.String(), accept)
+
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	assert.Equal(t.T(), "log line", string(body))
+}
+
+func (t *Test_LinkRequest_when_calling_get) Test_returns_error_when_content_type_mismatch() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/resource", t.serveLink(&Link{Href: "/logs", Type: MediaTypeOctetStream}))
+	ret.Mux.HandleFunc("/logs", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/html")
+		rw.Write([]byte("<html></html>"))
+	})
+
+	resp, err := ret.Client.Resource("/resource").Link("test").Get(context.Background())
+
+	assert.Nil(t.T(), resp)
+
+	_, ok := err.(*ErrUnexpectedContentType)
+	assert.True(t.T(), ok)
+}
+
+func (t *Test_LinkRequest_when_calling_get) Test_expands_templated_href() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	var requested string
+
+	ret.Mux.HandleFunc("/resource", t.serveLink(&Link{Href: "/devices/{id}/logs{?since,level}", Templated: true}))
+	ret.Mux.HandleFunc("/devices/{id}/logs", func(rw http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	resp, err := ret.Client.Resource("/resource").Link("test").
+		With(map[string]interface{}{
+			"id":    "dev 1",
+			"since": "2026-01-02T03:04:05Z",
+		}).
+		Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	resp.Body.Close()
+
+	assert.Equal(t.T(), "/devices/dev%201/logs?since=2026-01-02T03%3A04%3A05Z", requested)
+}
+
+func (t *Test_LinkRequest_when_calling_get) Test_decodes_single_and_multiple_links_per_relation() {
+	var resource *Resource
+
+	err := json.Unmarshal([]byte(`{"links":{
+		"logs":{"href":"/logs","title":"Logs"},
+		"alternate":[
+			{"href":"/status.json","name":"json","type":"application/json"},
+			{"href":"/status.txt","name":"text","hreflang":"en","deprecation":"https://example.com/deprecated"}
+		]
+	}}`), &resource)
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "Logs", resource.Link("logs").Title)
+	assert.Equal(t.T(), 2, len(resource.Links["alternate"]))
+	assert.Equal(t.T(), "/status.txt", resource.Links["alternate"].Named("text").Href)
+	assert.Equal(t.T(), "https://example.com/deprecated", resource.Links["alternate"].Named("text").Deprecation)
+	assert.Nil(t.T(), resource.Links["alternate"].Named("xml"))
+
+	b, err := json.Marshal(resource.Links)
+
+	assert.Nil(t.T(), err)
+	assert.True(t.T(), strings.Contains(string(b), `"logs":{"href":"/logs","title":"Logs"}`))
+	assert.True(t.T(), strings.Contains(string(b), `"alternate":[{`))
+}
+
+func (t *Test_LinkRequest_when_calling_get) Test_selects_named_link_and_honors_methods() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/resource", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, (&Resource{}).
+			AddLink("alternate",
+				&Link{Href: "/status.json", Name: "json"},
+				&Link{Href: "/status.txt", Name: "text", Title: "Plain status"},
+			).
+			AddLink("reset", &Link{Href: "/reset", Methods: []Method{POST}}))
+	})
+	ret.Mux.HandleFunc("/status.txt", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain")
+		rw.Write([]byte("ok"))
+	})
+
+	resp, err := ret.Client.Resource("/resource").Link("alternate").Named("text").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "Plain status", resp.Link.Title)
+	resp.Body.Close()
+
+	_, err = ret.Client.Resource("/resource").Link("alternate").Named("xml").Get(context.Background())
+
+	_, ok := err.(*ErrResourceNoSuchLink)
+	assert.True(t.T(), ok)
+
+	_, err = ret.Client.Resource("/resource").Link("reset").Get(context.Background())
+
+	_, ok = err.(*ErrMethodNotAllowed)
+	assert.True(t.T(), ok)
+}
+
+func (t *Test_LinkRequest_when_calling_get) Test_propagates_query_and_headers() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	requests := []string{}
+
+	ret.Mux.HandleFunc("/resource", func(rw http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI()+" "+r.Header.Get("X-Correlation-ID"))
+		t.serveLink(&Link{Href: "/logs?level=info"})(rw, r)
+	})
+	ret.Mux.HandleFunc("/logs", func(rw http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI()+" "+r.Header.Get("X-Correlation-ID"))
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	resource := ret.Client.Resource("/resource")
+
+	resp, err := resource.Link("test").
+		WithQuery(url.Values{"tail": []string{"100"}}).
+		WithHeader("X-Correlation-ID", "abc123").
+		Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	resp.Body.Close()
+
+	_, err = resource.Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), []string{
+		"/resource?tail=100 abc123",
+		"/logs?level=info&tail=100 abc123",
+		"/resource ",
+	}, requests)
+}
+
+func (t *Test_LinkRequest_when_calling_get) Test_head_describes_target_without_body() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	ret.Mux.HandleFunc("/resource", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, (&Resource{}).
+			AddLink("firmware", &Link{Href: "/firmware.bin", Type: MediaTypeOctetStream, Methods: []Method{GET}}).
+			AddLink("reset", &Link{Href: "/reset", Methods: []Method{POST}}).
+			AddLink("missing", &Link{Href: "/missing.bin"}))
+	})
+	ret.Mux.HandleFunc("/firmware.bin", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", MediaTypeOctetStream.String())
+		rw.Header().Set("Content-Length", "1048576")
+		rw.Header().Set("ETag", `"v7"`)
+		rw.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+	}).Methods("HEAD")
+
+	head, err := ret.Client.Resource("/resource").Link("firmware").Head(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), int64(1048576), head.ContentLength)
+	assert.Equal(t.T(), MediaTypeOctetStream, head.Type)
+	assert.Equal(t.T(), `"v7"`, head.ETag)
+	assert.True(t.T(), modified.Equal(head.LastModified))
+
+	_, err = ret.Client.Resource("/resource").Link("reset").Head(context.Background())
+
+	_, ok := err.(*ErrMethodNotAllowed)
+	assert.True(t.T(), ok)
+
+	_, err = ret.Client.Resource("/resource").Link("missing").Head(context.Background())
+
+	assert.True(t.T(), errors.Is(err, ErrNotFound))
+}
+
+func (t *Test_LinkRequest_when_calling_get) serveLink(link *Link) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{
+			Links: map[string]LinkSet{
+				"test": LinkSet{link},
+			},
+		})
+	}
+}
+
+func (t *Test_LinkRequest_when_calling_get) Test_credentials_not_sent_to_other_origins() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	other := newTestServerAndClient()
+	defer other.Server.Close()
+
+	var sameauth, otherauth string
+
+	ret.Mux.HandleFunc("/resource", func(rw http.ResponseWriter, r *http.Request) {
+		sameauth = r.Header.Get("Authorization")
+		writeTestResource(rw, (&Resource{}).AddLink("mirror", &Link{Href: other.Server.URL + "/mirror"}))
+	})
+
+	other.Mux.HandleFunc("/mirror", func(rw http.ResponseWriter, r *http.Request) {
+		otherauth = r.Header.Get("Authorization")
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	client := NewClient(&ClientConfig{
+		Auth: &testBearerAuth{token: "secret"},
+		Host: ret.Host,
+		Port: ret.Port,
+	})
+
+	resp, err := client.Resource("/resource").Link("mirror").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	resp.Body.Close()
+	assert.Equal(t.T(), "Bearer secret", sameauth)
+	assert.Equal(t.T(), "", otherauth)
+}
+
+type testBearerAuth struct {
+	token string
+}
+
+func (t *testBearerAuth) Sign(r *http.Request) {
+	r.Header.Set("Authorization", "Bearer "+t.token)
+}
+
+func TestRunLinkTestSuites(t *testing.T) {
+	suite.Run(t, new(Test_LinkRequest_when_calling_get))
+}
@@ -1,21 +1,36 @@
 package hmapi
 
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
 const (
-	MediaTypeHMAPIResource     = MediaType("application/vnd.hmapi.Resource+json")
-	MediaTypeHMAPIBoolean      = MediaType("application/vnd.hmapi.Bool")
-	MediaTypeHMAPIFloat32      = MediaType("application/vnd.hmapi.Float32")
-	MediaTypeHMAPIFloat64      = MediaType("application/vnd.hmapi.Float64")
-	MediaTypeHMAPIInt          = MediaType("application/vnd.hmapi.Int")
-	MediaTypeHMAPIInt32        = MediaType("application/vnd.hmapi.Int32")
-	MediaTypeHMAPIInt64        = MediaType("application/vnd.hmapi.Int64")
-	MediaTypeHMAPIString       = MediaType("application/vnd.hmapi.String")
-	MediaTypeHMAPIUInt         = MediaType("application/vnd.hmapi.UInt")
-	MediaTypeHMAPIUInt32       = MediaType("application/vnd.hmapi.UInt32")
-	MediaTypeHMAPIUInt64       = MediaType("application/vnd.hmapi.UInt64")
-	MediaTypeOctetStream       = MediaType("application/octet-stream")
-	MediaTypeJSON              = MediaType("application/json")
-	MediaTypeTextPlain         = MediaType("text/plain")
-	MediaTypeMultipartFormData = MediaType(`multipart/form-data;boundary="hmapi_boundry_E58FCE5B6201466A8A9A6ECCDFBD31D3"`)
+	MediaTypeHMAPIResource        = MediaType("application/vnd.hmapi.Resource+json")
+	MediaTypeHMAPIResourceCBOR    = MediaType("application/vnd.hmapi.Resource+cbor")
+	MediaTypeHMAPIResourceMsgPack = MediaType("application/vnd.hmapi.Resource+msgpack")
+	MediaTypeHMAPIDate            = MediaType("application/vnd.hmapi.Date")
+	MediaTypeHMAPIDateTime        = MediaType("application/vnd.hmapi.DateTime")
+	MediaTypeHMAPIDuration        = MediaType("application/vnd.hmapi.Duration") // ISO 8601, e.g. "PT1H30M"; see FormatDuration
+	MediaTypeHMAPIObject          = MediaType("application/vnd.hmapi.Object")
+	MediaTypeHMAPIArray           = MediaType("application/vnd.hmapi.Array")
+	MediaTypeHMAPIBoolean         = MediaType("application/vnd.hmapi.Bool")
+	MediaTypeHMAPIFloat32         = MediaType("application/vnd.hmapi.Float32")
+	MediaTypeHMAPIFloat64         = MediaType("application/vnd.hmapi.Float64")
+	MediaTypeHMAPIInt             = MediaType("application/vnd.hmapi.Int")
+	MediaTypeHMAPIInt32           = MediaType("application/vnd.hmapi.Int32")
+	MediaTypeHMAPIInt64           = MediaType("application/vnd.hmapi.Int64")
+	MediaTypeHMAPIString          = MediaType("application/vnd.hmapi.String")
+	MediaTypeHMAPIUInt            = MediaType("application/vnd.hmapi.UInt")
+	MediaTypeHMAPIUInt32          = MediaType("application/vnd.hmapi.UInt32")
+	MediaTypeHMAPIUInt64          = MediaType("application/vnd.hmapi.UInt64")
+	MediaTypeOctetStream          = MediaType("application/octet-stream")
+	MediaTypeJSON                 = MediaType("application/json")
+	MediaTypeProblemJSON          = MediaType("application/problem+json")
+	MediaTypeTextPlain            = MediaType("text/plain")
+	MediaTypeFormURLEncoded       = MediaType("application/x-www-form-urlencoded")
+	MediaTypeMultipartFormData    = MediaType(`multipart/form-data;boundary="hmapi_boundry_E58FCE5B6201466A8A9A6ECCDFBD31D3"`)
 )
 
 type MediaType string
@@ -23,3 +38,53 @@ type MediaType string
 func (t MediaType) String() string {
 	return string(t)
 }
+
+func mediaTypeBase(media MediaType) MediaType {
+	base, _, err := mime.ParseMediaType(media.String())
+
+	if err != nil {
+		return MediaType(strings.ToLower(strings.TrimSpace(media.String())))
+	}
+
+	return MediaType(base)
+}
+
+func mediaTypeEqual(a MediaType, b MediaType) bool {
+	return mediaTypeBase(a) == mediaTypeBase(b)
+}
+
+func mediaTypeMatches(accept MediaType, actual MediaType) bool {
+	accept = mediaTypeBase(accept)
+	actual = mediaTypeBase(actual)
+
+	if accept == "*/*" || accept == actual {
+		return true
+	}
+
+	if strings.HasSuffix(accept.String(), "/*") {
+		return strings.HasPrefix(actual.String(), strings.TrimSuffix(accept.String(), "*"))
+	}
+
+	return false
+}
+
+func acceptHeader(medias []MediaType) string {
+	values := make([]string, 0, len(medias))
+
+	for i, media := range medias {
+		q := 1.0 - float64(i)/10
+
+		if i == 0 {
+			values = append(values, media.String())
+			continue
+		}
+
+		if q < 0.1 {
+			q = 0.1
+		}
+
+		values = append(values, media.String()+";q="+strconv.FormatFloat(q, 'f', 1, 64))
+	}
+
+	return strings.Join(values, ", ")
+}
@@ -1,19 +1,19 @@
 package hmapi
 
-type method string
+type Method string
 
-func (t method) String() string {
+func (t Method) String() string {
 	return string(t)
 }
 
 const (
-	CONNECT = method("CONNECT")
-	DELETE  = method("DELETE")
-	GET     = method("GET")
-	HEAD    = method("HEAD")
-	OPTIONS = method("OPTIONS")
-	PATCH   = method("PATCH")
-	POST    = method("POST")
-	PUT     = method("PUT")
-	TRACE   = method("TRACE")
+	CONNECT = Method("CONNECT")
+	DELETE  = Method("DELETE")
+	GET     = Method("GET")
+	HEAD    = Method("HEAD")
+	OPTIONS = Method("OPTIONS")
+	PATCH   = Method("PATCH")
+	POST    = Method("POST")
+	PUT     = Method("PUT")
+	TRACE   = Method("TRACE")
 )
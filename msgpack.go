@@ -0,0 +1,407 @@
+package hmapi
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+type msgpackFormat struct{}
+
+func (t msgpackFormat) marshal(v interface{}) ([]byte, error) {
+	return t.append(nil, v)
+}
+
+func (t msgpackFormat) append(b []byte, v interface{}) ([]byte, error) {
+	switch value := v.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+
+	case bool:
+		if value {
+			return append(b, 0xc3), nil
+		}
+
+		return append(b, 0xc2), nil
+
+	case json.Number:
+		if i, err := strconv.ParseInt(value.String(), 10, 64); err == nil {
+			return t.appendInt(b, i), nil
+		}
+
+		if u, err := strconv.ParseUint(value.String(), 10, 64); err == nil {
+			return t.appendUint(b, u), nil
+		}
+
+		f, err := value.Float64()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return t.appendFloat(b, f), nil
+
+	case float64:
+		return t.appendFloat(b, value), nil
+
+	case int64:
+		return t.appendInt(b, value), nil
+
+	case uint64:
+		return t.appendUint(b, value), nil
+
+	case string:
+		n := len(value)
+
+		switch {
+		case n < 32:
+			b = append(b, 0xa0|byte(n))
+		case n <= math.MaxUint8:
+			b = append(b, 0xd9, byte(n))
+		case n <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+		}
+
+		return append(b, value...), nil
+
+	case []byte:
+		n := len(value)
+
+		switch {
+		case n <= math.MaxUint8:
+			b = append(b, 0xc4, byte(n))
+		case n <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+		}
+
+		return append(b, value...), nil
+
+	case []interface{}:
+		var err error
+
+		b = t.appendContainer(b, 0x90, 0xdc, len(value))
+
+		for _, item := range value {
+			if b, err = t.append(b, item); err != nil {
+				return nil, err
+			}
+		}
+
+		return b, nil
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+
+		for key := range value {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		var err error
+
+		b = t.appendContainer(b, 0x80, 0xde, len(value))
+
+		for _, key := range keys {
+			if b, err = t.append(b, key); err != nil {
+				return nil, err
+			}
+
+			if b, err = t.append(b, value[key]); err != nil {
+				return nil, err
+			}
+		}
+
+		return b, nil
+	}
+
+	return nil, fmt.Errorf("msgpack: unsupported value of type %T", v)
+}
+
+func (t msgpackFormat) appendContainer(b []byte, fix byte, code byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, code), uint16(n))
+	}
+
+	return binary.BigEndian.AppendUint32(append(b, code+1), uint32(n))
+}
+
+func (t msgpackFormat) appendInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0:
+		return t.appendUint(b, uint64(i))
+	case i >= -32:
+		return append(b, byte(int8(i)))
+	case i >= math.MinInt8:
+		return append(b, 0xd0, byte(int8(i)))
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(int16(i)))
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(int32(i)))
+	}
+
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(i))
+}
+
+func (t msgpackFormat) appendUint(b []byte, u uint64) []byte {
+	switch {
+	case u < 128:
+		return append(b, byte(u))
+	case u <= math.MaxUint8:
+		return append(b, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(u))
+	case u <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(u))
+	}
+
+	return binary.BigEndian.AppendUint64(append(b, 0xcf), u)
+}
+
+func (t msgpackFormat) appendFloat(b []byte, f float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(f))
+}
+
+func (t msgpackFormat) unmarshal(data []byte) (interface{}, error) {
+	decoder := &msgpackDecoder{data: data}
+
+	v, err := decoder.decode(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if decoder.pos != len(data) {
+		return nil, decoder.malformed("trailing data")
+	}
+
+	return v, nil
+}
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (t *msgpackDecoder) malformed(reason string) error {
+	return &ErrCodecMalformed{
+		MediaType: MediaTypeHMAPIResourceMsgPack,
+		Reason:    reason,
+	}
+}
+
+func (t *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(t.data)-t.pos < n {
+		return nil, t.malformed("unexpected end of data")
+	}
+
+	b := t.data[t.pos : t.pos+n]
+	t.pos += n
+	return b, nil
+}
+
+func (t *msgpackDecoder) uint(size int) (uint64, error) {
+	b, err := t.next(size)
+
+	if err != nil {
+		return 0, err
+	}
+
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	}
+
+	return binary.BigEndian.Uint64(b), nil
+}
+
+func (t *msgpackDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxGenericDepth {
+		return nil, t.malformed("maximum nesting depth exceeded")
+	}
+
+	b, err := t.next(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	code := b[0]
+
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xf0 == 0x80:
+		return t.decodeMap(int(code&0x0f), depth)
+	case code&0xf0 == 0x90:
+		return t.decodeArray(int(code&0x0f), depth)
+	case code&0xe0 == 0xa0:
+		return t.decodeString(int(code & 0x1f))
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+
+	case 0xc4, 0xc5, 0xc6:
+		n, err := t.uint(1 << (code - 0xc4))
+
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := t.next(t.length(n))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return append([]byte(nil), b...), nil
+
+	case 0xca:
+		n, err := t.uint(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := t.uint(8)
+		return math.Float64frombits(n), err
+
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := t.uint(1 << (code - 0xcc))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+
+		return int64(n), nil
+
+	case 0xd0:
+		n, err := t.uint(1)
+		return int64(int8(n)), err
+	case 0xd1:
+		n, err := t.uint(2)
+		return int64(int16(n)), err
+	case 0xd2:
+		n, err := t.uint(4)
+		return int64(int32(n)), err
+	case 0xd3:
+		n, err := t.uint(8)
+		return int64(n), err
+
+	case 0xd9, 0xda, 0xdb:
+		n, err := t.uint(1 << (code - 0xd9))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return t.decodeString(t.length(n))
+
+	case 0xdc, 0xdd:
+		n, err := t.uint(2 << (code - 0xdc))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return t.decodeArray(t.length(n), depth)
+
+	case 0xde, 0xdf:
+		n, err := t.uint(2 << (code - 0xde))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return t.decodeMap(t.length(n), depth)
+	}
+
+	return nil, t.malformed(fmt.Sprintf("unsupported type code 0x%x", code))
+}
+
+func (t *msgpackDecoder) length(n uint64) int {
+	if n > uint64(len(t.data)-t.pos) {
+		return -1
+	}
+
+	return int(n)
+}
+
+func (t *msgpackDecoder) decodeString(n int) (interface{}, error) {
+	b, err := t.next(n)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (t *msgpackDecoder) decodeArray(n int, depth int) (interface{}, error) {
+	if n < 0 || n > len(t.data)-t.pos {
+		return nil, t.malformed("length exceeds available data")
+	}
+
+	items := make([]interface{}, 0, n)
+
+	for i := 0; i < n; i++ {
+		item, err := t.decode(depth + 1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (t *msgpackDecoder) decodeMap(n int, depth int) (interface{}, error) {
+	if n < 0 || n > len(t.data)-t.pos {
+		return nil, t.malformed("length exceeds available data")
+	}
+
+	entries := make(map[string]interface{}, n)
+
+	for i := 0; i < n; i++ {
+		key, err := t.decode(depth + 1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := t.decode(depth + 1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries[genericKey(key)] = value
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,257 @@
+package hmapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	OperationContentState    = "state"
+	OperationContentProgress = "progress"
+	OperationFormCancel      = "cancel"
+	OperationLinkStatus      = "status"
+)
+
+type OperationState string
+
+func (t OperationState) String() string {
+	return string(t)
+}
+
+func (t OperationState) Done() bool {
+	return t == OperationSucceeded || t == OperationFailed || t == OperationCanceled
+}
+
+const (
+	OperationPending   = OperationState("pending")
+	OperationRunning   = OperationState("running")
+	OperationSucceeded = OperationState("succeeded")
+	OperationFailed    = OperationState("failed")
+	OperationCanceled  = OperationState("canceled")
+)
+
+var (
+	operationPollInterval    = 1 * time.Second
+	operationMaxPollInterval = 30 * time.Second
+)
+
+type Operation interface {
+	Poll(ctx context.Context) (*Resource, error)
+	Wait(ctx context.Context) (*Resource, error)
+	Cancel(ctx context.Context) (*FormResponse, error)
+	State() OperationState
+	Progress() float64
+	Status() *Resource
+}
+
+type operation struct {
+	mu         sync.Mutex
+	client     *client
+	href       string
+	state      OperationState
+	progress   float64
+	status     *Resource
+	retryAfter time.Duration
+	interval   time.Duration
+}
+
+func newOperation(client *client, resp *http.Response) (*operation, error) {
+	defer drainAndClose(resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return &operation{
+			client:   client,
+			state:    OperationSucceeded,
+			progress: 1,
+		}, nil
+	default:
+		return nil, responseError(resp.Request, resp, http.StatusAccepted, nil)
+	}
+
+	op := &operation{
+		client:   client,
+		href:     resp.Header.Get("Location"),
+		state:    OperationPending,
+		interval: operationPollInterval,
+	}
+
+	op.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	if op.href == "" {
+		var resource *Resource
+
+		if err := json.NewDecoder(resp.Body).Decode(&resource); err == nil && resource != nil {
+			if link := resource.Link(OperationLinkStatus); link != nil {
+				op.href = link.Href
+			}
+		}
+	}
+
+	if op.href == "" {
+		return nil, &ErrOperationNoStatus{
+			ClientRequest:  redactRequest(resp.Request),
+			ClientResponse: redactResponse(resp),
+		}
+	}
+
+	op.href = resolveSelf(resp, op.href)
+	return op, nil
+}
+
+func (t *operation) Poll(ctx context.Context) (*Resource, error) {
+	if t.href == "" {
+		return t.Status(), nil
+	}
+
+	resource, resp, err := t.client.Resource(t.href).(*resourceRequest).get(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.status = resource
+	t.state = OperationSucceeded
+	t.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	if content, ok := resource.Content[OperationContentState]; ok {
+		if state, ok := content.Value.(string); ok && state != "" {
+			t.state = OperationState(state)
+		}
+	}
+
+	if content, ok := resource.Content[OperationContentProgress]; ok {
+		if progress, ok := contentFloat(content); ok {
+			t.progress = progress
+		}
+	}
+
+	return resource, nil
+}
+
+func (t *operation) Wait(ctx context.Context) (*Resource, error) {
+	for {
+		if state := t.State(); state.Done() {
+			if state != OperationSucceeded {
+				return t.Status(), &ErrOperationFailed{
+					State:  state,
+					Status: t.Status(),
+				}
+			}
+
+			return t.Status(), nil
+		}
+
+		timer := time.NewTimer(t.nextInterval())
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if _, err := t.Poll(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *operation) Cancel(ctx context.Context) (*FormResponse, error) {
+	if t.href == "" {
+		return nil, &ErrResourceNoSuchForm{
+			FormName: OperationFormCancel,
+		}
+	}
+
+	return t.client.Resource(t.href).Form(OperationFormCancel).Submit(ctx)
+}
+
+func (t *operation) State() OperationState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.state
+}
+
+func (t *operation) Progress() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.progress
+}
+
+func (t *operation) Status() *Resource {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.status
+}
+
+func (t *operation) nextInterval() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.retryAfter != noRetryAfter {
+		wait := t.retryAfter
+		t.retryAfter = noRetryAfter
+		return wait
+	}
+
+	wait := t.interval
+	t.interval *= 2
+
+	if t.interval > operationMaxPollInterval {
+		t.interval = operationMaxPollInterval
+	}
+
+	return wait
+}
+
+const noRetryAfter = time.Duration(-1)
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return noRetryAfter
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+
+		return 0
+	}
+
+	return noRetryAfter
+}
+
+func contentFloat(content *Content) (float64, bool) {
+	switch value := content.Value.(type) {
+	case float64:
+		return value, true
+	case float32:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case json.Number:
+		f, err := value.Float64()
+		return f, err == nil
+	}
+
+	return 0, false
+}
@@ -0,0 +1,255 @@
+package hmapi
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type Test_Operation_when_form_accepted struct {
+	suite.Suite
+}
+
+func (t *Test_Operation_when_form_accepted) Test_wait_polls_until_succeeded() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	var polls int32
+
+	ret.Mux.HandleFunc("/firmware", t.serveInstallForm()).Methods("GET")
+	ret.Mux.HandleFunc("/firmware/install", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Location", "/operations/1")
+		rw.Header().Set("Retry-After", "0")
+		rw.WriteHeader(http.StatusAccepted)
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/operations/1", func(rw http.ResponseWriter, r *http.Request) {
+		state := OperationRunning
+		n := atomic.AddInt32(&polls, 1)
+
+		if n >= 3 {
+			state = OperationSucceeded
+		}
+
+		rw.Header().Set("Retry-After", "0")
+		writeTestResource(rw, &Resource{
+			Content: map[string]*Content{
+				OperationContentState:    &Content{Type: MediaTypeHMAPIString, Value: state},
+				OperationContentProgress: &Content{Type: MediaTypeHMAPIFloat64, Value: float64(n) / 3},
+			},
+		})
+	}).Methods("GET")
+
+	op, err := ret.Client.Resource("/firmware").Form("install").AddFieldAsString("version", "2.0").Start(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.NotNil(t.T(), op)
+	assert.Equal(t.T(), OperationPending, op.State())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status, err := op.Wait(ctx)
+
+	assert.Nil(t.T(), err)
+	assert.NotNil(t.T(), status)
+	assert.Equal(t.T(), OperationSucceeded, op.State())
+	assert.Equal(t.T(), float64(1), op.Progress())
+	assert.Equal(t.T(), int32(3), atomic.LoadInt32(&polls))
+}
+
+func (t *Test_Operation_when_form_accepted) Test_relative_status_hrefs_resolved_against_request() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	var polled []string
+
+	ret.Mux.HandleFunc("/firmware", t.serveInstallForm()).Methods("GET")
+	ret.Mux.HandleFunc("/firmware/install", func(rw http.ResponseWriter, r *http.Request) {
+		if r.FormValue("version") == "1.0" {
+			rw.Header().Set("Location", "operations/1")
+			rw.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		rw.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(rw).Encode((&Resource{}).AddLink(OperationLinkStatus, &Link{Href: "operations/2"}))
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/firmware/operations/{id}", func(rw http.ResponseWriter, r *http.Request) {
+		polled = append(polled, r.URL.Path)
+		writeTestResource(rw, &Resource{
+			Content: map[string]*Content{
+				OperationContentState: &Content{Type: MediaTypeHMAPIString, Value: OperationSucceeded},
+			},
+		})
+	}).Methods("GET")
+
+	for _, version := range []string{"1.0", "2.0"} {
+		op, err := ret.Client.Resource("/firmware").Form("install").AddFieldAsString("version", version).Start(context.Background())
+
+		assert.Nil(t.T(), err)
+
+		_, err = op.Poll(context.Background())
+
+		assert.Nil(t.T(), err)
+	}
+
+	assert.Equal(t.T(), []string{"/firmware/operations/1", "/firmware/operations/2"}, polled)
+}
+
+func (t *Test_Operation_when_form_accepted) Test_wait_returns_error_when_operation_failed() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/firmware", t.serveInstallForm()).Methods("GET")
+	ret.Mux.HandleFunc("/firmware/install", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Retry-After", "0")
+		rw.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(rw).Encode(&Resource{
+			Links: map[string]LinkSet{
+				OperationLinkStatus: LinkSet{&Link{Href: "/operations/2", Type: MediaTypeHMAPIResource}},
+			},
+		})
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/operations/2", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{
+			Content: map[string]*Content{
+				OperationContentState: &Content{Type: MediaTypeHMAPIString, Value: OperationFailed},
+			},
+		})
+	}).Methods("GET")
+
+	op, err := ret.Client.Resource("/firmware").Form("install").AddFieldAsString("version", "2.0").Start(context.Background())
+
+	assert.Nil(t.T(), err)
+
+	status, err := op.Wait(context.Background())
+
+	assert.NotNil(t.T(), status)
+	assert.NotNil(t.T(), err)
+
+	e, ok := err.(*ErrOperationFailed)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), OperationFailed, e.State)
+}
+
+func (t *Test_Operation_when_form_accepted) Test_cancel_submits_published_cancel_form() {
+	ret := newTestServerAndClient()
+	defer ret.Server.Close()
+
+	var canceled int32
+
+	ret.Mux.HandleFunc("/firmware", t.serveInstallForm()).Methods("GET")
+	ret.Mux.HandleFunc("/firmware/install", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Location", "/operations/3")
+		rw.WriteHeader(http.StatusAccepted)
+	}).Methods("POST")
+
+	ret.Mux.HandleFunc("/operations/3", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{
+			Forms: map[string]*Form{
+				OperationFormCancel: &Form{
+					Action:  "/operations/3/cancel",
+					Method:  POST,
+					Enctype: MediaTypeMultipartFormData,
+				},
+			},
+			Content: map[string]*Content{
+				OperationContentState: &Content{Type: MediaTypeHMAPIString, Value: OperationRunning},
+			},
+		})
+	}).Methods("GET")
+
+	ret.Mux.HandleFunc("/operations/3/cancel", func(rw http.ResponseWriter, r *http.Request) {
+		atomic.StoreInt32(&canceled, 1)
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	op, err := ret.Client.Resource("/firmware").Form("install").Start(context.Background())
+
+	assert.Nil(t.T(), err)
+
+	resp, err := op.Cancel(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t.T(), int32(1), atomic.LoadInt32(&canceled))
+}
+
+func (t *Test_Operation_when_form_accepted) Test_parses_retry_after_values() {
+	assert.Equal(t.T(), 3*time.Second, parseRetryAfter("3"))
+	assert.Equal(t.T(), time.Duration(0), parseRetryAfter("Mon, 02 Jan 2006 15:04:05 GMT"))
+	assert.Equal(t.T(), noRetryAfter, parseRetryAfter(""))
+	assert.Equal(t.T(), noRetryAfter, parseRetryAfter("soon"))
+}
+
+func (t *Test_Operation_when_form_accepted) serveInstallForm() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{
+			Forms: map[string]*Form{
+				"install": &Form{
+					Action:  "/firmware/install",
+					Method:  POST,
+					Enctype: MediaTypeMultipartFormData,
+					Fields: []*FormField{
+						&FormField{
+							Name: "version",
+							Type: MediaTypeHMAPIString,
+						},
+					},
+				},
+			},
+		})
+	}
+}
+
+type testServerAndClient struct {
+	Mux    *mux.Router
+	Host   string
+	Port   int
+	Server *httptest.Server
+	Client Client
+}
+
+// newTestServerAndClient starts a test server routing through a fresh mux and
+// returns a client for it. Suites register their own routes on ret.Mux.
+func newTestServerAndClient() (ret testServerAndClient) {
+	mux := mux.NewRouter()
+	svr := httptest.NewServer(mux)
+
+	url, _ := url.Parse(svr.URL)
+
+	hoststr, portstr, _ := net.SplitHostPort(url.Host)
+	port, _ := strconv.ParseInt(portstr, 10, 0)
+
+	client := NewClient(&ClientConfig{
+		Auth:   &AuthNone{},
+		Host:   hoststr,
+		Port:   int(port),
+		Scheme: HTTP,
+	})
+
+	ret.Mux = mux
+	ret.Host = hoststr
+	ret.Port = int(port)
+	ret.Server = svr
+	ret.Client = client
+	return
+}
+
+func TestRunOperationTestSuites(t *testing.T) {
+	suite.Run(t, new(Test_Operation_when_form_accepted))
+}
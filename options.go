@@ -0,0 +1,216 @@
+package hmapi
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Options holds the methods a target allows and, per method, the request
+// media types it accepts. Accept is filled from the Accept-Post and
+// Accept-Patch headers.
+type Options struct {
+	Allow  []Method
+	Accept map[Method][]MediaType
+}
+
+func (t *Options) Allows(m Method) bool {
+	for _, allowed := range t.Allow {
+		if strings.EqualFold(allowed.String(), m.String()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *resourceRequest) Options(ctx context.Context) (*Options, error) {
+	href, err := t.url()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return requestOptions(ctx, t.client, href, t.header)
+}
+
+func (t *formRequest) Options(ctx context.Context) (*Options, error) {
+	hmform, err := t.form(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := t.action(hmform, t.submissionFields(hmform))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if action, err = applyQuery(t.resource.client.url(action), t.query); err != nil {
+		return nil, err
+	}
+
+	return requestOptions(ctx, t.resource.client, action, t.resource.header)
+}
+
+func requestOptions(ctx context.Context, client *client, href string, header http.Header) (*Options, error) {
+	request, err := http.NewRequest(OPTIONS.String(), href, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	request = request.WithContext(ctx)
+	applyHeader(request, header)
+
+	resp, err := client.do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer drainAndClose(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, responseError(request, resp, http.StatusNoContent, nil)
+	}
+
+	options := &Options{
+		Allow:  []Method{},
+		Accept: map[Method][]MediaType{},
+	}
+
+	for _, allowed := range splitHeader(resp.Header, "Allow") {
+		options.Allow = append(options.Allow, Method(strings.ToUpper(allowed)))
+	}
+
+	for m, name := range acceptHeaders {
+		for _, media := range splitHeader(resp.Header, name) {
+			if !containsMediaType(options.Accept[m], MediaType(media)) {
+				options.Accept[m] = append(options.Accept[m], MediaType(media))
+			}
+		}
+	}
+
+	return options, nil
+}
+
+// acceptHeaders maps the methods that have a header advertising their
+// request media types to that header.
+var acceptHeaders = map[Method]string{
+	POST:  "Accept-Post",
+	PATCH: "Accept-Patch",
+}
+
+func WriteOptions(rw http.ResponseWriter, allow []Method, accept map[Method][]MediaType) {
+	methods := []string{}
+
+	for _, m := range append(append([]Method{}, allow...), OPTIONS) {
+		if !containsString(methods, m.String()) {
+			methods = append(methods, m.String())
+		}
+	}
+
+	rw.Header().Set("Allow", strings.Join(methods, ", "))
+
+	for m, name := range acceptHeaders {
+		medias := make([]string, len(accept[m]))
+
+		for i, media := range accept[m] {
+			medias[i] = media.String()
+		}
+
+		if len(medias) > 0 && containsString(methods, m.String()) {
+			rw.Header().Set(name, strings.Join(medias, ", "))
+		}
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// ResourceOptions describes the methods and request media types a server
+// accepts for the resource itself, derived from the forms whose action is
+// empty or targets the resource's self link.
+func ResourceOptions(resource *Resource) *Options {
+	options := &Options{
+		Allow:  []Method{GET, HEAD},
+		Accept: map[Method][]MediaType{},
+	}
+
+	if resource == nil {
+		return options
+	}
+
+	names := make([]string, 0, len(resource.Forms))
+
+	for name := range resource.Forms {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		form := resource.Forms[name]
+
+		if form == nil || (form.Action != "" && form.Action != resource.Self) {
+			continue
+		}
+
+		m := Method(strings.ToUpper(form.Method.String()))
+
+		if m == "" || m == CONNECT || m == TRACE {
+			continue
+		}
+
+		if !options.Allows(m) {
+			options.Allow = append(options.Allow, m)
+		}
+
+		if form.Enctype != "" && !containsMediaType(options.Accept[m], form.Enctype) {
+			options.Accept[m] = append(options.Accept[m], form.Enctype)
+		}
+	}
+
+	return options
+}
+
+func WriteResourceOptions(rw http.ResponseWriter, resource *Resource) {
+	options := ResourceOptions(resource)
+	WriteOptions(rw, options.Allow, options.Accept)
+}
+
+func splitHeader(header http.Header, name string) []string {
+	values := []string{}
+
+	for _, line := range header[http.CanonicalHeaderKey(name)] {
+		for _, value := range strings.Split(line, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+
+	return values
+}
+
+func containsMediaType(medias []MediaType, media MediaType) bool {
+	for _, existing := range medias {
+		if mediaTypeEqual(existing, media) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,66 @@
+package hmapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deviceio/hmapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type Test_Options_when_served_from_another_package struct {
+	suite.Suite
+}
+
+func (t *Test_Options_when_served_from_another_package) Test_write_options_with_exported_methods() {
+	rw := httptest.NewRecorder()
+
+	hmapi.WriteOptions(rw, []hmapi.Method{hmapi.GET, hmapi.POST}, map[hmapi.Method][]hmapi.MediaType{
+		hmapi.POST:  []hmapi.MediaType{hmapi.MediaTypeJSON},
+		hmapi.PATCH: []hmapi.MediaType{hmapi.MediaTypeJSON},
+	})
+
+	assert.Equal(t.T(), http.StatusNoContent, rw.Code)
+	assert.Equal(t.T(), "GET, POST, OPTIONS", rw.Header().Get("Allow"))
+	assert.Equal(t.T(), hmapi.MediaTypeJSON.String(), rw.Header().Get("Accept-Post"))
+	assert.Equal(t.T(), "", rw.Header().Get("Accept-Patch"))
+}
+
+func (t *Test_Options_when_served_from_another_package) Test_resource_forms_advertised_on_options() {
+	device := &hmapi.Resource{
+		Self: "/devices/1",
+		Forms: map[string]*hmapi.Form{
+			"update":  &hmapi.Form{Action: "/devices/1", Method: hmapi.PATCH, Enctype: hmapi.MediaTypeJSON},
+			"replace": &hmapi.Form{Method: hmapi.PUT, Enctype: hmapi.MediaTypeMultipartFormData},
+			"reboot":  &hmapi.Form{Action: "/devices/1/reboot", Method: hmapi.POST, Enctype: hmapi.MediaTypeJSON},
+		},
+	}
+
+	ret := hmapi.NewTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/devices/1", func(rw http.ResponseWriter, r *http.Request) {
+		hmapi.WriteResource(rw, r, device)
+	})
+
+	options, err := ret.Client.Resource("/devices/1").Options(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), []hmapi.Method{hmapi.GET, hmapi.HEAD, hmapi.PUT, hmapi.PATCH, hmapi.OPTIONS}, options.Allow)
+	assert.Equal(t.T(), map[hmapi.Method][]hmapi.MediaType{
+		hmapi.PATCH: []hmapi.MediaType{hmapi.MediaTypeJSON},
+	}, options.Accept)
+	assert.False(t.T(), options.Allows(hmapi.POST))
+
+	resource, err := ret.Client.Resource("/devices/1").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 3, len(resource.Forms))
+}
+
+func TestRunOptionsTestSuites(t *testing.T) {
+	suite.Run(t, new(Test_Options_when_served_from_another_package))
+}
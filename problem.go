@@ -0,0 +1,129 @@
+package hmapi
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	maxErrorBodySnippet = 1024
+	maxProblemBody      = 64 * 1024
+)
+
+type ProblemParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type FieldError struct {
+	Name   string
+	Reason string
+	Field  *FormField
+}
+
+type ErrProblem struct {
+	Type          string          `json:"type,omitempty"`
+	Title         string          `json:"title,omitempty"`
+	Status        int             `json:"status,omitempty"`
+	Detail        string          `json:"detail,omitempty"`
+	Instance      string          `json:"instance,omitempty"`
+	InvalidParams []*ProblemParam `json:"invalid-params,omitempty"`
+
+	FieldErrors    []*FieldError  `json:"-"`
+	ClientRequest  *http.Request  `json:"-"`
+	ClientResponse *http.Response `json:"-"`
+}
+
+func (t *ErrProblem) Error() string {
+	title := t.Title
+
+	if title == "" {
+		title = http.StatusText(t.Status)
+	}
+
+	if t.Detail == "" {
+		return title
+	}
+
+	return title + ": " + t.Detail
+}
+
+func (t *ErrProblem) Unwrap() error {
+	return statusCategory(t.Status)
+}
+
+func (t *ErrProblem) mapFields(form *Form) {
+	t.FieldErrors = make([]*FieldError, 0, len(t.InvalidParams))
+
+	for _, param := range t.InvalidParams {
+		fielderr := &FieldError{
+			Name:   strings.TrimPrefix(strings.TrimPrefix(param.Name, "#"), "/"),
+			Reason: param.Reason,
+		}
+
+		if form != nil {
+			for _, field := range form.Fields {
+				if field.Name == fielderr.Name {
+					fielderr.Field = field
+					break
+				}
+			}
+		}
+
+		t.FieldErrors = append(t.FieldErrors, fielderr)
+	}
+}
+
+func WriteProblem(rw http.ResponseWriter, problem *ErrProblem) error {
+	if problem.Status == 0 {
+		problem.Status = http.StatusInternalServerError
+	}
+
+	rw.Header().Set("Content-Type", MediaTypeProblemJSON.String())
+	rw.WriteHeader(problem.Status)
+
+	return json.NewEncoder(rw).Encode(problem)
+}
+
+func responseError(request *http.Request, resp *http.Response, expected int, form *Form) error {
+	mediatype, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	isproblem := MediaType(mediatype) == MediaTypeProblemJSON
+	limit := int64(maxErrorBodySnippet)
+
+	if isproblem {
+		limit = maxProblemBody
+	}
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, limit))
+
+	if isproblem {
+		problem := &ErrProblem{}
+
+		if err := json.Unmarshal(body, problem); err == nil {
+			if problem.Status == 0 {
+				problem.Status = resp.StatusCode
+			}
+
+			problem.mapFields(form)
+			problem.ClientRequest = redactRequest(request)
+			problem.ClientResponse = redactResponse(resp)
+			return problem
+		}
+	}
+
+	if len(body) > maxErrorBodySnippet {
+		body = body[:maxErrorBodySnippet]
+	}
+
+	return &ErrUnexpectedHTTPResponseStatus{
+		ExpectedStatus: expected,
+		ActualStatus:   resp.StatusCode,
+		Body:           body,
+		ClientRequest:  redactRequest(request),
+		ClientResponse: redactResponse(resp),
+	}
+}
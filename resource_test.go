@@ -3,10 +3,14 @@ package hmapi
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"net/url"
 
@@ -60,11 +64,28 @@ func (t *Test_ResourceRequest_when_calling_get) Test_returns_error_when_non_http
 	assert.Equal(t.T(), http.StatusInternalServerError, e.ActualStatus)
 }
 
+func (t *Test_ResourceRequest_when_calling_get) Test_returns_error_with_bounded_body_snippet() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/resource", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+		rw.Write([]byte(strings.Repeat("x", maxErrorBodySnippet*2)))
+	})
+
+	_, err := ret.Client.Resource("/resource").Get(context.Background())
+
+	e, ok := err.(*ErrUnexpectedHTTPResponseStatus)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), maxErrorBodySnippet, len(e.Body))
+}
+
 func (t *Test_ResourceRequest_when_calling_get) Test_returns_error_when_failed_json_decode() {
 	ret := t.getTestServerAndClient()
 	defer ret.Server.Close()
 
 	ret.Mux.HandleFunc("/resource", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", MediaTypeHMAPIResource.String())
 		rw.Write([]byte("{some invalid json"))
 	})
 
@@ -84,11 +105,11 @@ func (t *Test_ResourceRequest_when_calling_get) Test_returns_minimal_resource()
 	ret.Mux.HandleFunc("/resource", func(rw http.ResponseWriter, r *http.Request) {
 		resource := &Resource{
 			Forms:   map[string]*Form{},
-			Links:   map[string]*Link{},
+			Links:   map[string]LinkSet{},
 			Content: map[string]*Content{},
 		}
 
-		json.NewEncoder(rw).Encode(resource)
+		writeTestResource(rw, resource)
 	})
 
 	resource, err := ret.Client.Resource("/resource").Get(context.Background())
@@ -101,6 +122,411 @@ func (t *Test_ResourceRequest_when_calling_get) Test_returns_minimal_resource()
 	assert.NotNil(t.T(), resource.Links)
 }
 
+func (t *Test_ResourceRequest_when_calling_get) Test_returns_self_describing_metadata() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/devices/1", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{
+			Title:       "Device 1",
+			Description: "lab gateway",
+			Profile:     "urn:deviceio:device",
+			Meta:        map[string]interface{}{"revision": "7"},
+		})
+	})
+
+	ret.Mux.HandleFunc("/devices/2", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{
+			Self: "/devices/2?view=full",
+		})
+	})
+
+	resource, err := ret.Client.Resource("/devices/1").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), ret.Server.URL+"/devices/1", resource.Self)
+	assert.Equal(t.T(), "Device 1", resource.Title)
+	assert.Equal(t.T(), "lab gateway", resource.Description)
+	assert.Equal(t.T(), "urn:deviceio:device", resource.Profile)
+	assert.Equal(t.T(), "7", resource.Meta["revision"])
+
+	resource, err = ret.Client.Resource("/devices/2").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), ret.Server.URL+"/devices/2?view=full", resource.Self)
+}
+
+func (t *Test_ResourceRequest_when_calling_get) Test_embedded_resources_used_without_fetching() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	var fetched, rebooted []string
+
+	device := func(id string) *Resource {
+		return &Resource{
+			Self: "/devices/" + id,
+			Forms: map[string]*Form{
+				"reboot": &Form{Action: "/devices/" + id + "/reboot", Method: POST, Enctype: MediaTypeFormURLEncoded},
+			},
+		}
+	}
+
+	ret.Mux.HandleFunc("/devices", func(rw http.ResponseWriter, r *http.Request) {
+		resource := &Resource{}
+
+		if EmbedRequested(r, "devices") {
+			resource.Embed("devices", device("1"), device("2"))
+		}
+
+		writeTestResource(rw, resource)
+	}).Methods("GET")
+
+	ret.Mux.HandleFunc("/devices/{id}", func(rw http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, mux.Vars(r)["id"])
+		writeTestResource(rw, device(mux.Vars(r)["id"]))
+	}).Methods("GET")
+
+	ret.Mux.HandleFunc("/devices/{id}/reboot", func(rw http.ResponseWriter, r *http.Request) {
+		rebooted = append(rebooted, mux.Vars(r)["id"])
+		rw.WriteHeader(http.StatusNoContent)
+	}).Methods("POST")
+
+	devices, err := ret.Client.Resource("/devices").Embedded(context.Background(), "devices")
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 2, len(devices))
+
+	for i, device := range devices {
+		resource, err := device.Get(context.Background())
+
+		assert.Nil(t.T(), err)
+		assert.Equal(t.T(), fmt.Sprintf("%v/devices/%v", ret.Server.URL, i+1), resource.Self)
+
+		_, err = device.Form("reboot").Submit(context.Background())
+		assert.Nil(t.T(), err)
+	}
+
+	assert.Equal(t.T(), 0, len(fetched))
+	assert.Equal(t.T(), []string{"1", "2"}, rebooted)
+
+	resource, err := ret.Client.Resource("/devices").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), resource.Embedded)
+
+	_, err = ret.Client.Resource("/devices").Embedded(context.Background(), "sensors")
+
+	_, ok := err.(*ErrResourceNoSuchEmbedded)
+	assert.True(t.T(), ok)
+}
+
+func (t *Test_ResourceRequest_when_calling_get) Test_embedded_does_not_change_request() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	var requested []string
+
+	ret.Mux.HandleFunc("/devices", func(rw http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RawQuery)
+		writeTestResource(rw, (&Resource{}).Embed("devices", &Resource{Self: "/devices/1"}))
+	}).Methods("GET")
+
+	request := ret.Client.Resource("/devices")
+
+	for i := 0; i < 2; i++ {
+		_, err := request.Embedded(context.Background(), "devices")
+		assert.Nil(t.T(), err)
+	}
+
+	_, err := request.Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), []string{"embed=devices", "embed=devices", ""}, requested)
+}
+
+func (t *Test_ResourceRequest_when_calling_get) Test_embedded_resource_without_self_is_not_addressable() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/devices", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, (&Resource{}).
+			Embed("devices", &Resource{Self: "devices/1", Title: "device-1"}).
+			Embed("sensors", &Resource{Title: "sensor-1"}))
+	}).Methods("GET")
+
+	resource, err := ret.Client.Resource("/devices").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), ret.Server.URL+"/devices/1", resource.Embedded["devices"][0].Self)
+	assert.Equal(t.T(), "", resource.Embedded["sensors"][0].Self)
+
+	_, err = ret.Client.FromResource(resource.Embedded["sensors"][0])
+
+	_, ok := err.(*ErrResourceNoSelf)
+	assert.True(t.T(), ok)
+
+	_, err = ret.Client.Resource("/devices").Embedded(context.Background(), "sensors")
+
+	_, ok = err.(*ErrResourceNoSelf)
+	assert.True(t.T(), ok)
+
+	devices, err := ret.Client.Resource("/devices").Embedded(context.Background(), "devices")
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 1, len(devices))
+}
+
+func (t *Test_ResourceRequest_when_calling_get) Test_sends_query_and_headers() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	var requested, language, correlation string
+
+	ret.Mux.HandleFunc("/devices", func(rw http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		language = r.Header.Get("Accept-Language")
+		correlation = r.Header.Get("X-Correlation-ID")
+		writeTestResource(rw, &Resource{})
+	})
+
+	_, err := ret.Client.Resource("/devices?sort=name").
+		WithQuery(url.Values{"q": []string{"lab & office"}}).
+		WithHeader("Accept-Language", "de").
+		WithHeader("X-Correlation-ID", "abc123").
+		Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "/devices?q=lab+%26+office&sort=name", requested)
+	assert.Equal(t.T(), "de", language)
+	assert.Equal(t.T(), "abc123", correlation)
+}
+
+func (t *Test_ResourceRequest_when_calling_get) Test_options_reports_allowed_methods_and_media_types() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/devices", func(rw http.ResponseWriter, r *http.Request) {
+		WriteOptions(rw, []Method{GET, HEAD, POST, PATCH}, map[Method][]MediaType{
+			POST:  []MediaType{MediaTypeMultipartFormData, MediaTypeJSON},
+			PATCH: []MediaType{MediaTypeFormURLEncoded},
+		})
+	}).Methods("OPTIONS")
+
+	ret.Mux.HandleFunc("/devices", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{
+			Forms: map[string]*Form{
+				"update": &Form{Action: "/devices/1", Method: PATCH},
+			},
+		})
+	}).Methods("GET")
+
+	ret.Mux.HandleFunc("/devices/1", func(rw http.ResponseWriter, r *http.Request) {
+		WriteOptions(rw, []Method{GET, PATCH}, map[Method][]MediaType{
+			PATCH: []MediaType{MediaTypeJSON},
+		})
+	}).Methods("OPTIONS")
+
+	options, err := ret.Client.Resource("/devices").Options(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), []Method{GET, HEAD, POST, PATCH, OPTIONS}, options.Allow)
+	assert.Equal(t.T(), []MediaType{MediaTypeMultipartFormData, MediaTypeJSON}, options.Accept[POST])
+	assert.Equal(t.T(), []MediaType{MediaTypeFormURLEncoded}, options.Accept[PATCH])
+	assert.True(t.T(), options.Allows(POST))
+	assert.False(t.T(), options.Allows(DELETE))
+
+	options, err = ret.Client.Resource("/devices").Form("update").Options(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.True(t.T(), options.Allows(PATCH))
+	assert.Equal(t.T(), []MediaType{MediaTypeJSON}, options.Accept[PATCH])
+	assert.Nil(t.T(), options.Accept[POST])
+
+	_, err = ret.Client.Resource("/missing").Options(context.Background())
+
+	assert.NotNil(t.T(), err)
+}
+
+func (t *Test_ResourceRequest_when_calling_get) Test_sends_accept_header_for_resource_media_types() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	var accept string
+
+	ret.Mux.HandleFunc("/resource", func(rw http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		writeTestResource(rw, &Resource{})
+	})
+
+	_, err := ret.Client.Resource("/resource").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "application/vnd.hmapi.Resource+json, application/json;q=0.9, application/vnd.hmapi.Resource+cbor;q=0.8, application/vnd.hmapi.Resource+msgpack;q=0.7", accept)
+}
+
+func (t *Test_ResourceRequest_when_calling_get) Test_returns_error_when_unexpected_content_type() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/resource", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		rw.Write([]byte("<html></html>"))
+	})
+
+	resource, err := ret.Client.Resource("/resource").Get(context.Background())
+
+	assert.Nil(t.T(), resource)
+
+	e, ok := err.(*ErrUnexpectedContentType)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), MediaType("text/html; charset=utf-8"), e.Actual)
+}
+
+func (t *Test_ResourceRequest_when_calling_get) Test_content_decodes_temporal_values() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	booted := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+
+	ret.Mux.HandleFunc("/resource", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{
+			Content: map[string]*Content{
+				"booted":   &Content{Type: MediaTypeHMAPIDateTime, Value: booted},
+				"uptime":   &Content{Type: MediaTypeHMAPIDuration, Value: (36 * time.Hour).String()},
+				"hostname": &Content{Type: MediaTypeHMAPIString, Value: "device1"},
+			},
+		})
+	})
+
+	resource, err := ret.Client.Resource("/resource").Get(context.Background())
+
+	assert.Nil(t.T(), err)
+
+	at, err := resource.Content["booted"].Time()
+
+	assert.Nil(t.T(), err)
+	assert.True(t.T(), booted.Equal(at))
+
+	uptime, err := resource.Content["uptime"].Duration()
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 36*time.Hour, uptime)
+
+	_, err = resource.Content["hostname"].Time()
+
+	_, ok := err.(*ErrContentType)
+	assert.True(t.T(), ok)
+}
+
+func (t *Test_ResourceRequest_when_calling_get) Test_connections_reused_across_responses() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/resource", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{})
+	})
+
+	ret.Mux.HandleFunc("/failure", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+		rw.Write([]byte(strings.Repeat("x", maxErrorBodySnippet*4)))
+	})
+
+	ret.Mux.HandleFunc("/invalid", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", MediaTypeHMAPIResource.String())
+		rw.Write([]byte("{some invalid json"))
+	})
+
+	transport := &countingTransport{}
+
+	client := NewClient(&ClientConfig{
+		Host:       ret.Host,
+		Port:       ret.Port,
+		HTTPClient: &http.Client{Transport: transport.init()},
+	})
+
+	for i := 0; i < 3; i++ {
+		for _, path := range []string{"/resource", "/failure", "/invalid"} {
+			client.Resource(path).Get(context.Background())
+		}
+	}
+
+	assert.Equal(t.T(), int32(1), atomic.LoadInt32(&transport.dials))
+}
+
+func (t *Test_ResourceRequest_when_calling_get) Test_connections_reused_across_link_form_and_content_responses() {
+	ret := t.getTestServerAndClient()
+	defer ret.Server.Close()
+
+	ret.Mux.HandleFunc("/device", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, (&Resource{
+			Forms: map[string]*Form{
+				"fail": &Form{Action: "/failure", Method: POST, Enctype: MediaTypeFormURLEncoded},
+			},
+			Content: map[string]*Content{
+				"text":    &Content{Type: MediaTypeTextPlain, Href: "/text"},
+				"failing": &Content{Type: MediaTypeTextPlain, Href: "/failure"},
+			},
+		}).
+			AddLink("ok", &Link{Href: "/resource"}).
+			AddLink("typed", &Link{Href: "/resource", Type: MediaTypeJSON}).
+			AddLink("fail", &Link{Href: "/failure"}))
+	})
+
+	ret.Mux.HandleFunc("/resource", func(rw http.ResponseWriter, r *http.Request) {
+		writeTestResource(rw, &Resource{Title: strings.Repeat("x", maxErrorBodySnippet*4)})
+	})
+
+	ret.Mux.HandleFunc("/text", func(rw http.ResponseWriter, r *http.Request) {
+		WriteContent(rw, MediaTypeTextPlain, strings.Repeat("x", maxErrorBodySnippet*4))
+	})
+
+	ret.Mux.HandleFunc("/failure", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+		rw.Write([]byte(strings.Repeat("x", maxErrorBodySnippet*4)))
+	})
+
+	transport := &countingTransport{}
+
+	client := NewClient(&ClientConfig{
+		Host:       ret.Host,
+		Port:       ret.Port,
+		HTTPClient: &http.Client{Transport: transport.init()},
+	})
+
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Resource("/device").Link("ok").Get(ctx)
+		assert.Nil(t.T(), err)
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		_, err = client.Resource("/device").Link("typed").Get(ctx)
+		assert.NotNil(t.T(), err)
+
+		_, err = client.Resource("/device").Link("fail").Head(ctx)
+		assert.NotNil(t.T(), err)
+
+		formresp, err := client.Resource("/device").Form("fail").Submit(ctx)
+		assert.Nil(t.T(), err)
+		_, err = formresp.Resource(ctx)
+		assert.NotNil(t.T(), err)
+
+		_, err = client.Resource("/device").Content("failing").Get(ctx)
+		assert.NotNil(t.T(), err)
+
+		_, err = client.Resource("/device").Content("text").Get(ctx)
+		assert.Nil(t.T(), err)
+
+		body, err := client.Resource("/device").Content("text").Open(ctx)
+		assert.Nil(t.T(), err)
+		ioutil.ReadAll(body)
+		body.Close()
+	}
+
+	assert.Equal(t.T(), int32(1), atomic.LoadInt32(&transport.dials))
+}
+
 func (t *Test_ResourceRequest_when_calling_get) getTestServerAndClient() (ret struct {
 	Mux    *mux.Router
 	Host   string
@@ -131,6 +557,26 @@ func (t *Test_ResourceRequest_when_calling_get) getTestServerAndClient() (ret st
 	return
 }
 
+func writeTestResource(rw http.ResponseWriter, resource *Resource) {
+	rw.Header().Set("Content-Type", MediaTypeHMAPIResource.String())
+	json.NewEncoder(rw).Encode(resource)
+}
+
+type countingTransport struct {
+	dials int32
+}
+
+func (t *countingTransport) init() *http.Transport {
+	dialer := &net.Dialer{}
+
+	return &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			atomic.AddInt32(&t.dials, 1)
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+}
+
 func TestResourceTestSuite(t *testing.T) {
 	suite.Run(t, new(Test_ResourceRequest_when_calling_get))
 }
@@ -0,0 +1,324 @@
+package hmapi
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultMaxMemory = 32 << 20
+
+const (
+	EmbedQueryParam    = "embed"
+	PageSizeQueryParam = "limit"
+)
+
+func WriteResource(rw http.ResponseWriter, r *http.Request, resource *Resource) error {
+	return WriteResourceStatus(rw, r, http.StatusOK, resource)
+}
+
+func WriteResourceStatus(rw http.ResponseWriter, r *http.Request, status int, resource *Resource) error {
+	if r != nil && r.Method == OPTIONS.String() {
+		WriteResourceOptions(rw, resource)
+		return nil
+	}
+
+	codec := NegotiateCodec(r)
+
+	rw.Header().Set("Content-Type", codec.MediaType().String())
+	rw.Header().Add("Vary", "Accept")
+
+	if resource != nil && resource.ETag != "" {
+		rw.Header().Set("ETag", resource.ETag)
+	}
+
+	rw.WriteHeader(status)
+
+	return codec.Encode(rw, resource)
+}
+
+func NegotiateCodec(r *http.Request) Codec {
+	var accept string
+
+	if r != nil {
+		accept = r.Header.Get("Accept")
+	}
+
+	for _, media := range parseAccept(accept) {
+		if codec, ok := LookupCodec(media); ok {
+			return codec
+		}
+
+		if mediaTypeMatches(media, MediaTypeHMAPIResource) {
+			break
+		}
+	}
+
+	codec, _ := LookupCodec(MediaTypeHMAPIResource)
+	return codec
+}
+
+func parseAccept(accept string) []MediaType {
+	type acceptEntry struct {
+		media MediaType
+		q     float64
+	}
+
+	entries := []acceptEntry{}
+
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		media := strings.TrimSpace(params[0])
+
+		if media == "" {
+			continue
+		}
+
+		entry := acceptEntry{
+			media: MediaType(media),
+			q:     1,
+		}
+
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+
+			if len(kv) == 2 && strings.EqualFold(kv[0], "q") {
+				if q, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					entry.q = q
+				}
+			}
+		}
+
+		if entry.q > 0 {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].q > entries[j].q
+	})
+
+	medias := make([]MediaType, len(entries))
+
+	for i, entry := range entries {
+		medias[i] = entry.media
+	}
+
+	return medias
+}
+
+func CheckPreconditions(rw http.ResponseWriter, r *http.Request, etag string) bool {
+	if ifmatch := r.Header.Get("If-Match"); ifmatch != "" && !etagMatches(ifmatch, etag, false) {
+		WriteProblem(rw, &ErrProblem{
+			Title:  "Precondition Failed",
+			Status: http.StatusPreconditionFailed,
+			Detail: "the resource has been modified",
+		})
+
+		return false
+	}
+
+	if ifnonematch := r.Header.Get("If-None-Match"); ifnonematch != "" && etagMatches(ifnonematch, etag, true) {
+		if r.Method == GET.String() || r.Method == HEAD.String() {
+			rw.Header().Set("ETag", etag)
+			rw.WriteHeader(http.StatusNotModified)
+			return false
+		}
+
+		WriteProblem(rw, &ErrProblem{
+			Title:  "Precondition Failed",
+			Status: http.StatusPreconditionFailed,
+			Detail: "the resource already exists",
+		})
+
+		return false
+	}
+
+	return true
+}
+
+func etagMatches(header string, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		switch {
+		case candidate == "*":
+			return true
+		case weak && strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/"):
+			return true
+		case !weak && !strings.HasPrefix(candidate, "W/") && !strings.HasPrefix(etag, "W/") && candidate == etag:
+			return true
+		}
+	}
+
+	return false
+}
+
+func NewContent(media MediaType, value interface{}, href string, maxInline int) (*Content, error) {
+	encoder, ok := LookupFieldEncoder(media)
+
+	if !ok {
+		return nil, &ErrUnsupportedMediaType{
+			MediaType: media,
+		}
+	}
+
+	if reader, stream := value.(io.Reader); stream {
+		if href != "" {
+			return &Content{Type: media, Href: href}, nil
+		}
+
+		b, err := ioutil.ReadAll(reader)
+
+		if err != nil {
+			return nil, err
+		}
+
+		value = b
+	}
+
+	text, err := encoder.EncodeText(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if href != "" && len(text) > maxInline {
+		return &Content{Type: media, Href: href}, nil
+	}
+
+	inline, err := encoder.EncodeJSON(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Content{Type: media, Value: inline}, nil
+}
+
+func WriteContent(rw http.ResponseWriter, media MediaType, value interface{}) error {
+	encoder, ok := LookupFieldEncoder(media)
+
+	if !ok {
+		return &ErrUnsupportedMediaType{
+			MediaType: media,
+		}
+	}
+
+	rw.Header().Set("Content-Type", media.String())
+
+	if streamer, ok := encoder.(FieldStreamEncoder); ok {
+		return streamer.EncodeStream(rw, value)
+	}
+
+	text, err := encoder.EncodeText(value)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(rw, text)
+	return err
+}
+
+func EmbedRequested(r *http.Request, rel string) bool {
+	for _, value := range r.URL.Query()[EmbedQueryParam] {
+		for _, requested := range strings.Split(value, ",") {
+			if strings.TrimSpace(requested) == rel {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func FormValue(r *http.Request, name string, media MediaType) (interface{}, error) {
+	text := r.FormValue(name)
+
+	if text == "" {
+		tree, found, err := decodeFormValues(r, name, media)
+
+		if found || err != nil {
+			return tree, err
+		}
+	}
+
+	value, err := ParseFieldText(media, text)
+
+	if err != nil {
+		return nil, &ErrInvalidFieldValue{
+			FieldName: name,
+			MediaType: media,
+			Err:       err,
+		}
+	}
+
+	return value, nil
+}
+
+// decodeFormValues reads a field submitted in bracket notation, as written by
+// encoders implementing FieldValuesEncoder for url encoded forms.
+func decodeFormValues(r *http.Request, name string, media MediaType) (interface{}, bool, error) {
+	encoder, ok := LookupFieldEncoder(media)
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	decoder, ok := encoder.(FieldValuesDecoder)
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	tree, found, err := decoder.DecodeValues(name, r.Form)
+
+	if err != nil {
+		return nil, true, &ErrInvalidFieldValue{
+			FieldName: name,
+			MediaType: media,
+			Err:       err,
+		}
+	}
+
+	return tree, found, nil
+}
+
+func FormValueTime(r *http.Request, name string) (time.Time, error) {
+	value, err := FormValue(r, name, MediaTypeHMAPIDateTime)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return value.(time.Time), nil
+}
+
+func FormValueDate(r *http.Request, name string) (time.Time, error) {
+	value, err := FormValue(r, name, MediaTypeHMAPIDate)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return value.(time.Time), nil
+}
+
+func FormValueDuration(r *http.Request, name string) (time.Duration, error) {
+	value, err := FormValue(r, name, MediaTypeHMAPIDuration)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return value.(time.Duration), nil
+}
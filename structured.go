@@ -0,0 +1,282 @@
+package hmapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type jsonFieldEncoder struct {
+	mediaType MediaType
+	kind      reflect.Kind
+}
+
+func (t *jsonFieldEncoder) MediaType() MediaType {
+	return t.mediaType
+}
+
+func (t *jsonFieldEncoder) PartContentType() MediaType {
+	return MediaTypeJSON
+}
+
+func (t *jsonFieldEncoder) EncodeText(value interface{}) (string, error) {
+	tree, err := t.tree(value)
+
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(tree)
+	return string(b), err
+}
+
+func (t *jsonFieldEncoder) EncodeJSON(value interface{}) (interface{}, error) {
+	return t.tree(value)
+}
+
+func (t *jsonFieldEncoder) EncodeValues(name string, value interface{}, values url.Values) error {
+	tree, err := t.tree(value)
+
+	if err != nil {
+		return err
+	}
+
+	return encodeBracketValues(name, tree, values)
+}
+
+// DecodeValues rebuilds a value written by EncodeValues from name[key]
+// entries. Bracket notation carries no JSON types, so leaves decode as
+// strings.
+func (t *jsonFieldEncoder) DecodeValues(name string, values url.Values) (interface{}, bool, error) {
+	root := map[string]interface{}{}
+	keys := []string{}
+
+	for key := range values {
+		if strings.HasPrefix(key, name+"[") {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, false, nil
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path, ok := bracketPath(key[len(name):])
+
+		if !ok {
+			return nil, true, fmt.Errorf("malformed bracket key '%v'", key)
+		}
+
+		if err := insertBracketValue(root, path, values.Get(key)); err != nil {
+			return nil, true, fmt.Errorf("conflicting bracket key '%v': %v", key, err)
+		}
+	}
+
+	bracketChildren(root)
+
+	var tree interface{} = root
+
+	if t.kind == reflect.Slice {
+		items, ok := bracketItems(root)
+
+		if !ok {
+			return nil, true, t.checkKind(root)
+		}
+
+		tree = items
+	}
+
+	return tree, true, nil
+}
+
+func (t *jsonFieldEncoder) DecodeText(text string) (interface{}, error) {
+	tree, err := jsonTree([]byte(text))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = t.checkKind(tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+func (t *jsonFieldEncoder) tree(value interface{}) (interface{}, error) {
+	b, err := json.Marshal(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := jsonTree(b)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = t.checkKind(tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+func (t *jsonFieldEncoder) checkKind(tree interface{}) error {
+	switch tree.(type) {
+	case map[string]interface{}:
+		if t.kind == reflect.Map {
+			return nil
+		}
+	case []interface{}:
+		if t.kind == reflect.Slice {
+			return nil
+		}
+	}
+
+	expected := "object"
+
+	if t.kind == reflect.Slice {
+		expected = "array"
+	}
+
+	return fmt.Errorf("value of type %T is not a json %v", tree, expected)
+}
+
+func jsonTree(b []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var tree interface{}
+
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+func bracketPath(key string) ([]string, bool) {
+	if !strings.HasPrefix(key, "[") || !strings.HasSuffix(key, "]") {
+		return nil, false
+	}
+
+	path := strings.Split(key[1:len(key)-1], "][")
+
+	for _, segment := range path {
+		if segment == "" || strings.ContainsAny(segment, "[]") {
+			return nil, false
+		}
+	}
+
+	return path, true
+}
+
+func insertBracketValue(node map[string]interface{}, path []string, text string) error {
+	for _, segment := range path[:len(path)-1] {
+		child, ok := node[segment]
+
+		if !ok {
+			child = map[string]interface{}{}
+			node[segment] = child
+		}
+
+		if node, ok = child.(map[string]interface{}); !ok {
+			return fmt.Errorf("'%v' is both a value and a container", segment)
+		}
+	}
+
+	last := path[len(path)-1]
+
+	if _, exists := node[last]; exists {
+		return fmt.Errorf("'%v' is both a value and a container", last)
+	}
+
+	node[last] = text
+	return nil
+}
+
+// bracketChildren turns nested containers keyed 0..n-1 into arrays.
+func bracketChildren(node map[string]interface{}) {
+	for key, child := range node {
+		m, ok := child.(map[string]interface{})
+
+		if !ok {
+			continue
+		}
+
+		bracketChildren(m)
+
+		if items, ok := bracketItems(m); ok {
+			node[key] = items
+		}
+	}
+}
+
+func bracketItems(node map[string]interface{}) ([]interface{}, bool) {
+	items := make([]interface{}, len(node))
+
+	for key, child := range node {
+		i, err := strconv.Atoi(key)
+
+		if err != nil || i < 0 || i >= len(items) || strconv.Itoa(i) != key {
+			return nil, false
+		}
+
+		items[i] = child
+	}
+
+	return items, len(items) > 0
+}
+
+func encodeBracketValues(name string, tree interface{}, values url.Values) error {
+	switch tree := tree.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(tree))
+
+		for key := range tree {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if err := encodeBracketValues(name+"["+key+"]", tree[key], values); err != nil {
+				return err
+			}
+		}
+
+	case []interface{}:
+		for i, item := range tree {
+			if err := encodeBracketValues(name+"["+strconv.Itoa(i)+"]", item, values); err != nil {
+				return err
+			}
+		}
+
+	case nil:
+		values.Add(name, "")
+
+	case bool:
+		values.Add(name, strconv.FormatBool(tree))
+
+	case json.Number:
+		values.Add(name, tree.String())
+
+	case string:
+		values.Add(name, tree)
+
+	default:
+		return fmt.Errorf("unsupported json value of type %T", tree)
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package hmapi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+type timeFieldEncoder struct {
+	mediaType MediaType
+	layout    string
+}
+
+func (t *timeFieldEncoder) MediaType() MediaType {
+	return t.mediaType
+}
+
+func (t *timeFieldEncoder) EncodeText(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case time.Time:
+		return value.Format(t.layout), nil
+	case *time.Time:
+		if value != nil {
+			return value.Format(t.layout), nil
+		}
+	case string:
+		parsed, err := time.Parse(t.layout, value)
+
+		if err != nil {
+			return "", err
+		}
+
+		return parsed.Format(t.layout), nil
+	}
+
+	return "", fmt.Errorf("expected time.Time value, got %T", value)
+}
+
+func (t *timeFieldEncoder) EncodeJSON(value interface{}) (interface{}, error) {
+	return t.EncodeText(value)
+}
+
+func (t *timeFieldEncoder) DecodeText(text string) (interface{}, error) {
+	return time.Parse(t.layout, text)
+}
+
+type durationFieldEncoder struct{}
+
+func (t *durationFieldEncoder) MediaType() MediaType {
+	return MediaTypeHMAPIDuration
+}
+
+func (t *durationFieldEncoder) EncodeText(value interface{}) (string, error) {
+	d, err := toDuration(value)
+
+	if err != nil {
+		return "", err
+	}
+
+	return FormatDuration(d), nil
+}
+
+func (t *durationFieldEncoder) EncodeJSON(value interface{}) (interface{}, error) {
+	return t.EncodeText(value)
+}
+
+func (t *durationFieldEncoder) DecodeText(text string) (interface{}, error) {
+	return ParseDuration(text)
+}
+
+func toDuration(value interface{}) (time.Duration, error) {
+	switch value := value.(type) {
+	case time.Duration:
+		return value, nil
+	case string:
+		return ParseDuration(value)
+	}
+
+	i, err := toInt64(value, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("expected time.Duration value, got %T", value)
+	}
+
+	return time.Duration(i), nil
+}
+
+// FormatDuration writes d as an ISO 8601 duration using only the hour,
+// minute and second designators, e.g. "PT1H30M" or "-PT0.5S".
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+
+	b := strings.Builder{}
+	u := uint64(d)
+
+	if d < 0 {
+		b.WriteString("-")
+		u = -u
+	}
+
+	b.WriteString("PT")
+
+	if h := u / uint64(time.Hour); h > 0 {
+		fmt.Fprintf(&b, "%dH", h)
+		u -= h * uint64(time.Hour)
+	}
+
+	if m := u / uint64(time.Minute); m > 0 {
+		fmt.Fprintf(&b, "%dM", m)
+		u -= m * uint64(time.Minute)
+	}
+
+	if u > 0 {
+		fmt.Fprintf(&b, "%d", u/uint64(time.Second))
+
+		if ns := u % uint64(time.Second); ns > 0 {
+			b.WriteString("." + strings.TrimRight(fmt.Sprintf("%09d", ns), "0"))
+		}
+
+		b.WriteString("S")
+	}
+
+	return b.String()
+}
+
+// ParseDuration reads an ISO 8601 duration. Weeks and days count as 7 and 1
+// times 24 hours; years and months are rejected because their length
+// varies. Go duration strings such as "1h30m0s" are still accepted from
+// peers that predate the ISO format.
+func ParseDuration(text string) (time.Duration, error) {
+	s := strings.TrimPrefix(strings.TrimPrefix(text, "-"), "+")
+
+	if !strings.HasPrefix(s, "P") {
+		return time.ParseDuration(text)
+	}
+
+	invalid := fmt.Errorf("invalid ISO 8601 duration %q", text)
+	s = s[1:]
+
+	var total time.Duration
+	intime, seen := false, false
+
+	for s != "" {
+		if s[0] == 'T' {
+			if intime || len(s) == 1 {
+				return 0, invalid
+			}
+
+			intime = true
+			s = s[1:]
+			continue
+		}
+
+		i := strings.IndexFunc(s, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.' && r != ','
+		})
+
+		if i <= 0 {
+			return 0, invalid
+		}
+
+		var unit time.Duration
+
+		switch {
+		case !intime && s[i] == 'W':
+			unit = 7 * 24 * time.Hour
+		case !intime && s[i] == 'D':
+			unit = 24 * time.Hour
+		case intime && s[i] == 'H':
+			unit = time.Hour
+		case intime && s[i] == 'M':
+			unit = time.Minute
+		case intime && s[i] == 'S':
+			unit = time.Second
+		default:
+			return 0, invalid
+		}
+
+		whole, frac := s[:i], ""
+
+		if j := strings.IndexAny(whole, ".,"); j >= 0 {
+			whole, frac = whole[:j], whole[j+1:]
+		}
+
+		n, err := strconv.ParseInt(whole, 10, 64)
+
+		if err != nil || n > int64(math.MaxInt64/unit) {
+			return 0, invalid
+		}
+
+		value := time.Duration(n) * unit
+
+		if frac != "" {
+			f, err := strconv.ParseFloat("0."+frac, 64)
+
+			if err != nil {
+				return 0, invalid
+			}
+
+			value += time.Duration(f * float64(unit))
+		}
+
+		if value > math.MaxInt64-total {
+			return 0, invalid
+		}
+
+		total += value
+		seen = true
+		s = s[i+1:]
+	}
+
+	if !seen {
+		return 0, invalid
+	}
+
+	if strings.HasPrefix(text, "-") {
+		total = -total
+	}
+
+	return total, nil
+}
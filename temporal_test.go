@@ -0,0 +1,58 @@
+package hmapi
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type Test_Duration_when_encoding struct {
+	suite.Suite
+}
+
+func (t *Test_Duration_when_encoding) Test_durations_round_trip_as_iso_8601() {
+	for _, entry := range []struct {
+		d    time.Duration
+		text string
+	}{
+		{0, "PT0S"},
+		{90 * time.Minute, "PT1H30M"},
+		{36 * time.Hour, "PT36H"},
+		{1500 * time.Millisecond, "PT1.5S"},
+		{-time.Nanosecond, "-PT0.000000001S"},
+		{math.MaxInt64, "PT2562047H47M16.854775807S"},
+	} {
+		assert.Equal(t.T(), entry.text, FormatDuration(entry.d))
+
+		d, err := ParseDuration(entry.text)
+
+		assert.Nil(t.T(), err, entry.text)
+		assert.Equal(t.T(), entry.d, d, entry.text)
+	}
+}
+
+func (t *Test_Duration_when_encoding) Test_parses_days_weeks_and_go_durations() {
+	for text, expected := range map[string]time.Duration{
+		"P1DT2H":  26 * time.Hour,
+		"P1W":     7 * 24 * time.Hour,
+		"PT0,25S": 250 * time.Millisecond,
+		"1h30m0s": 90 * time.Minute,
+	} {
+		d, err := ParseDuration(text)
+
+		assert.Nil(t.T(), err, text)
+		assert.Equal(t.T(), expected, d, text)
+	}
+
+	for _, text := range []string{"P", "PT", "P1M", "P1Y", "PT1D", "P1H", "PTS", "P1DT", "PT1.2.3S", "PT9999999999H"} {
+		_, err := ParseDuration(text)
+		assert.NotNil(t.T(), err, text)
+	}
+}
+
+func TestRunDurationTestSuites(t *testing.T) {
+	suite.Run(t, new(Test_Duration_when_encoding))
+}
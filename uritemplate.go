@@ -0,0 +1,199 @@
+package hmapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+type uriTemplateOperator struct {
+	first   string
+	sep     string
+	named   bool
+	ifEmpty string
+	allowR  bool
+}
+
+var uriTemplateOperators = map[byte]*uriTemplateOperator{
+	'+': {first: "", sep: ",", allowR: true},
+	'#': {first: "#", sep: ",", allowR: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+}
+
+var uriTemplateSimple = &uriTemplateOperator{first: "", sep: ","}
+
+// ExpandURITemplate expands a level 3 RFC 6570 URI template. Variables that
+// are missing or nil are treated as undefined and omitted from the result.
+func ExpandURITemplate(template string, vars map[string]interface{}) (string, error) {
+	var b strings.Builder
+
+	for len(template) > 0 {
+		start := strings.IndexAny(template, "{}")
+
+		if start < 0 {
+			b.WriteString(uriTemplateEncode(template, true))
+			break
+		}
+
+		if template[start] == '}' {
+			return "", &ErrURITemplate{
+				Template: template,
+				Reason:   "unmatched '}'",
+			}
+		}
+
+		end := strings.IndexByte(template[start:], '}')
+
+		if end < 0 {
+			return "", &ErrURITemplate{
+				Template: template,
+				Reason:   "unterminated expression",
+			}
+		}
+
+		b.WriteString(uriTemplateEncode(template[:start], true))
+
+		expanded, err := expandURITemplateExpression(template[start+1:start+end], vars)
+
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(expanded)
+		template = template[start+end+1:]
+	}
+
+	return b.String(), nil
+}
+
+func expandURITemplateExpression(expr string, vars map[string]interface{}) (string, error) {
+	op := uriTemplateSimple
+
+	if expr != "" {
+		if found, ok := uriTemplateOperators[expr[0]]; ok {
+			op = found
+			expr = expr[1:]
+		}
+	}
+
+	names, err := uriTemplateVarNames(expr)
+
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{}
+
+	for _, name := range names {
+		value, ok := vars[name]
+
+		if !ok || value == nil {
+			continue
+		}
+
+		text := uriTemplateEncode(fmt.Sprint(value), op.allowR)
+
+		switch {
+		case !op.named:
+			parts = append(parts, text)
+		case text == "":
+			parts = append(parts, name+op.ifEmpty)
+		default:
+			parts = append(parts, name+"="+text)
+		}
+	}
+
+	if len(parts) == 0 {
+		return "", nil
+	}
+
+	return op.first + strings.Join(parts, op.sep), nil
+}
+
+func uriTemplateVarNames(expr string) ([]string, error) {
+	names := strings.Split(expr, ",")
+
+	for _, name := range names {
+		if name == "" || strings.ContainsAny(name, "{}=,!@|*:") {
+			return nil, &ErrURITemplate{
+				Template: expr,
+				Reason:   fmt.Sprintf("invalid variable name '%v'", name),
+			}
+		}
+	}
+
+	return names, nil
+}
+
+func uriTemplateVars(template string) []string {
+	names := []string{}
+
+	for {
+		start := strings.IndexByte(template, '{')
+
+		if start < 0 {
+			return names
+		}
+
+		end := strings.IndexByte(template[start:], '}')
+
+		if end < 0 {
+			return names
+		}
+
+		expr := template[start+1 : start+end]
+
+		if expr != "" {
+			if _, ok := uriTemplateOperators[expr[0]]; ok {
+				expr = expr[1:]
+			}
+		}
+
+		if found, err := uriTemplateVarNames(expr); err == nil {
+			names = append(names, found...)
+		}
+
+		template = template[start+end+1:]
+	}
+}
+
+func uriTemplateEncode(s string, allowReserved bool) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case uriTemplateUnreserved(c):
+			b.WriteByte(c)
+		case allowReserved && uriTemplateReserved(c):
+			b.WriteByte(c)
+		case allowReserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			b.WriteString(s[i : i+3])
+			i += 2
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0x0f])
+		}
+	}
+
+	return b.String()
+}
+
+func uriTemplateUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-._~", c) >= 0
+}
+
+func uriTemplateReserved(c byte) bool {
+	return strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,407 @@
+package hmapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"regexp"
+	"unicode/utf8"
+)
+
+func (t *Form) Field(name string, media MediaType) *FormField {
+	field := &FormField{
+		Name: name,
+		Type: media,
+	}
+
+	t.Fields = append(t.Fields, field)
+	return field
+}
+
+func (t *FormField) Require() *FormField {
+	t.Required = true
+	return t
+}
+
+func (t *FormField) WithTitle(title string, description string) *FormField {
+	t.Title = title
+	t.Description = description
+	return t
+}
+
+func (t *FormField) WithOption(value interface{}, label string) *FormField {
+	t.Options = append(t.Options, &FormFieldOption{
+		Value: value,
+		Label: label,
+	})
+
+	return t
+}
+
+func (t *FormField) WithRange(min float64, max float64) *FormField {
+	t.Min = &min
+	t.Max = &max
+	return t
+}
+
+func (t *FormField) WithLength(min int, max int) *FormField {
+	t.MinLength = &min
+	t.MaxLength = &max
+	return t
+}
+
+func (t *FormField) WithPattern(pattern string) *FormField {
+	t.Pattern = pattern
+	return t
+}
+
+func (t *FormField) WithDefault(value interface{}) *FormField {
+	t.Default = value
+	return t
+}
+
+func (t *Form) ValidateRequest(r *http.Request) error {
+	raw, err := requestFieldValues(r, t.Fields)
+
+	if err != nil {
+		return err
+	}
+
+	fielderrs := []*FieldError{}
+
+	for _, field := range t.Fields {
+		values, reasons := field.parseValues(raw[field.Name])
+
+		if len(reasons) == 0 {
+			reasons = field.validate(values)
+		}
+
+		fielderrs = appendFieldErrors(fielderrs, field, reasons)
+	}
+
+	return validationError(fielderrs)
+}
+
+// requestFieldValues returns the submitted values of fields by name, as text
+// for url encoded and multipart bodies and as decoded JSON for JSON bodies. A
+// JSON array only holds several values when the field is Multiple. A JSON
+// body is restored so the handler can read it again.
+func requestFieldValues(r *http.Request, fields []*FormField) (map[string][]interface{}, error) {
+	values := map[string][]interface{}{}
+
+	if mediaTypeEqual(MediaType(r.Header.Get("Content-Type")), MediaTypeJSON) {
+		b, err := ioutil.ReadAll(r.Body)
+
+		if err != nil {
+			return nil, err
+		}
+
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+		tree, err := jsonTree(b)
+
+		if err != nil {
+			return nil, err
+		}
+
+		object, ok := tree.(map[string]interface{})
+
+		if !ok {
+			return nil, fmt.Errorf("expected a JSON object body, got %T", tree)
+		}
+
+		for _, field := range fields {
+			value := object[field.Name]
+
+			if mediaTypeEqual(field.Type, MediaTypeOctetStream) {
+				if value, err = decodeJSONBytes(value); err != nil {
+					return nil, &ErrInvalidFieldValue{
+						FieldName: field.Name,
+						MediaType: field.Type,
+						Err:       err,
+					}
+				}
+			}
+
+			switch value := value.(type) {
+			case nil:
+			case []interface{}:
+				if field.Multiple {
+					values[field.Name] = value
+				} else {
+					values[field.Name] = []interface{}{value}
+				}
+			default:
+				values[field.Name] = []interface{}{value}
+			}
+		}
+
+		return values, nil
+	}
+
+	if r.Form == nil {
+		if err := r.ParseMultipartForm(defaultMaxMemory); err != nil && err != http.ErrNotMultipart {
+			return nil, err
+		}
+	}
+
+	for _, field := range fields {
+		for _, text := range r.Form[field.Name] {
+			values[field.Name] = append(values[field.Name], text)
+		}
+
+		if len(values[field.Name]) > 0 {
+			continue
+		}
+
+		tree, found, err := decodeFormValues(r, field.Name, field.Type)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			values[field.Name] = []interface{}{tree}
+		}
+	}
+
+	return values, nil
+}
+
+// requestFieldValue converts a submitted text or decoded JSON value to the
+// media type's Go value.
+func requestFieldValue(media MediaType, value interface{}) (interface{}, error) {
+	text, ok := value.(string)
+
+	if !ok {
+		encoder, found := LookupFieldEncoder(media)
+
+		if !found {
+			return nil, &ErrUnsupportedMediaType{
+				MediaType: media,
+			}
+		}
+
+		encoded, err := encoder.EncodeText(value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		text = encoded
+	}
+
+	return ParseFieldText(media, text)
+}
+
+// decodeJSONBytes decodes the base64 strings encoding/json writes for []byte
+// values, keeping list structure intact.
+func decodeJSONBytes(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case string:
+		return base64.StdEncoding.DecodeString(value)
+
+	case []interface{}:
+		items := make([]interface{}, len(value))
+
+		for i, item := range value {
+			decoded, err := decodeJSONBytes(item)
+
+			if err != nil {
+				return nil, err
+			}
+
+			items[i] = decoded
+		}
+
+		return items, nil
+	}
+
+	return value, nil
+}
+
+func (t *Form) validate(values map[string][]interface{}) error {
+	fielderrs := []*FieldError{}
+
+	for _, field := range t.Fields {
+		fielderrs = appendFieldErrors(fielderrs, field, field.validate(values[field.Name]))
+	}
+
+	return validationError(fielderrs)
+}
+
+func appendFieldErrors(fielderrs []*FieldError, field *FormField, reasons []string) []*FieldError {
+	for _, reason := range reasons {
+		fielderrs = append(fielderrs, &FieldError{
+			Name:   field.Name,
+			Reason: reason,
+			Field:  field,
+		})
+	}
+
+	return fielderrs
+}
+
+func validationError(fielderrs []*FieldError) error {
+	if len(fielderrs) > 0 {
+		return &ErrValidation{
+			FieldErrors: fielderrs,
+		}
+	}
+
+	return nil
+}
+
+// parseValues converts submitted values to the field's type. Fields with a
+// type that has no registered encoder are validated as submitted.
+func (t *FormField) parseValues(raw []interface{}) ([]interface{}, []string) {
+	if _, ok := LookupFieldEncoder(t.Type); !ok {
+		return raw, nil
+	}
+
+	values := []interface{}{}
+	reasons := []string{}
+
+	for _, value := range raw {
+		parsed, err := requestFieldValue(t.Type, value)
+
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("is not a valid %v", t.Type))
+			continue
+		}
+
+		values = append(values, parsed)
+	}
+
+	return values, reasons
+}
+
+func (t *FormField) validate(values []interface{}) []string {
+	reasons := []string{}
+
+	if len(values) == 0 {
+		if t.Required {
+			reasons = append(reasons, "is required")
+		}
+
+		return reasons
+	}
+
+	if len(values) > 1 && !t.Multiple {
+		reasons = append(reasons, "does not accept multiple values")
+	}
+
+	var pattern *regexp.Regexp
+
+	if t.Pattern != "" {
+		compiled, err := regexp.Compile("^(?:" + t.Pattern + ")$")
+
+		if err != nil {
+			return append(reasons, fmt.Sprintf("has an invalid pattern: %v", err))
+		}
+
+		pattern = compiled
+	}
+
+	for _, value := range values {
+		reasons = append(reasons, t.validateValue(value, pattern)...)
+	}
+
+	return reasons
+}
+
+func (t *FormField) validateValue(value interface{}, pattern *regexp.Regexp) []string {
+	reasons := []string{}
+
+	if len(t.Options) > 0 && !t.isOption(value) {
+		reasons = append(reasons, "is not one of the permitted options")
+	}
+
+	if number, ok := numericValue(value); ok {
+		if t.Min != nil && number < *t.Min {
+			reasons = append(reasons, fmt.Sprintf("must be at least %v", *t.Min))
+		}
+
+		if t.Max != nil && number > *t.Max {
+			reasons = append(reasons, fmt.Sprintf("must be at most %v", *t.Max))
+		}
+	}
+
+	if length, ok := lengthValue(value); ok {
+		if t.MinLength != nil && length < *t.MinLength {
+			reasons = append(reasons, fmt.Sprintf("must have a length of at least %v", *t.MinLength))
+		}
+
+		if t.MaxLength != nil && length > *t.MaxLength {
+			reasons = append(reasons, fmt.Sprintf("must have a length of at most %v", *t.MaxLength))
+		}
+	}
+
+	if text, ok := value.(string); ok && pattern != nil && !pattern.MatchString(text) {
+		reasons = append(reasons, fmt.Sprintf("must match pattern '%v'", t.Pattern))
+	}
+
+	return reasons
+}
+
+func (t *FormField) isOption(value interface{}) bool {
+	encoder, hasEncoder := LookupFieldEncoder(t.Type)
+	text := fmt.Sprint(value)
+
+	if hasEncoder {
+		if encoded, err := encoder.EncodeText(value); err == nil {
+			text = encoded
+		}
+	}
+
+	for _, option := range t.Options {
+		optiontext := fmt.Sprint(option.Value)
+
+		if hasEncoder {
+			if encoded, err := encoder.EncodeText(option.Value); err == nil {
+				optiontext = encoded
+			}
+		}
+
+		if text == optiontext {
+			return true
+		}
+	}
+
+	return false
+}
+
+func numericValue(value interface{}) (float64, bool) {
+	if number, ok := value.(json.Number); ok {
+		f, err := number.Float64()
+		return f, err == nil
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		f, err := toFloat64(value, 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+func lengthValue(value interface{}) (int, bool) {
+	if text, ok := value.(string); ok {
+		return utf8.RuneCountInString(text), true
+	}
+
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,173 @@
+package hmapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type Test_Form_when_validating struct {
+	suite.Suite
+}
+
+func (t *Test_Form_when_validating) Test_constraints_emitted_in_form_json() {
+	form := &Form{Action: "/device/network", Method: POST, Enctype: MediaTypeFormURLEncoded}
+	form.Field("port", MediaTypeHMAPIInt).WithRange(1, 65535).WithDefault(80).WithTitle("Port", "listen port")
+
+	b, err := json.Marshal(form)
+
+	assert.Nil(t.T(), err)
+	assert.True(t.T(), strings.Contains(string(b), `"min":1,"max":65535`))
+	assert.True(t.T(), strings.Contains(string(b), `"default":80`))
+	assert.True(t.T(), strings.Contains(string(b), `"title":"Port","description":"listen port"`))
+
+	var decoded *Form
+
+	assert.Nil(t.T(), json.Unmarshal(b, &decoded))
+	assert.Equal(t.T(), float64(65535), *decoded.Fields[0].Max)
+}
+
+func (t *Test_Form_when_validating) Test_validates_request_on_server() {
+	form := &Form{Action: "/device/network", Method: POST, Enctype: MediaTypeFormURLEncoded}
+	form.Field("port", MediaTypeHMAPIInt).Require().WithRange(1, 65535)
+	form.Field("mode", MediaTypeHMAPIString).WithOption("dhcp", "").WithOption("static", "")
+
+	request, _ := http.NewRequest(POST.String(), "/device/network", strings.NewReader(url.Values{
+		"port": []string{"0"},
+		"mode": []string{"dhcp"},
+	}.Encode()))
+	request.Header.Set("Content-Type", MediaTypeFormURLEncoded.String())
+
+	err := form.ValidateRequest(request)
+
+	e, ok := err.(*ErrValidation)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), 1, len(e.FieldErrors))
+	assert.Equal(t.T(), "port", e.FieldErrors[0].Name)
+	assert.Equal(t.T(), "must be at least 1", e.FieldErrors[0].Reason)
+}
+
+func (t *Test_Form_when_validating) Test_rejects_values_of_the_wrong_type() {
+	form := &Form{Action: "/device/network", Method: POST, Enctype: MediaTypeFormURLEncoded}
+	form.Field("port", MediaTypeHMAPIInt).Require().WithRange(1, 65535)
+
+	request, _ := http.NewRequest(POST.String(), "/device/network", strings.NewReader(url.Values{
+		"port": []string{"abc"},
+	}.Encode()))
+	request.Header.Set("Content-Type", MediaTypeFormURLEncoded.String())
+
+	err := form.ValidateRequest(request)
+
+	e, ok := err.(*ErrValidation)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), 1, len(e.FieldErrors))
+	assert.Equal(t.T(), "is not a valid "+MediaTypeHMAPIInt.String(), e.FieldErrors[0].Reason)
+}
+
+func (t *Test_Form_when_validating) Test_validates_json_request_and_keeps_body() {
+	form := &Form{Action: "/device/network", Method: POST, Enctype: MediaTypeJSON}
+	form.Field("port", MediaTypeHMAPIInt).Require().WithRange(1, 65535)
+	form.Field("dns", MediaTypeHMAPIString).WithPattern(`[0-9.]+`).Multiple = true
+
+	body := `{"port":70000,"dns":["1.1.1.1","dns.local"]}`
+	request, _ := http.NewRequest(POST.String(), "/device/network", strings.NewReader(body))
+	request.Header.Set("Content-Type", MediaTypeJSON.String())
+
+	err := form.ValidateRequest(request)
+
+	e, ok := err.(*ErrValidation)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), 2, len(e.FieldErrors))
+	assert.Equal(t.T(), "must be at most 65535", e.FieldErrors[0].Reason)
+	assert.Equal(t.T(), "must match pattern '[0-9.]+'", e.FieldErrors[1].Reason)
+
+	b, _ := ioutil.ReadAll(request.Body)
+	assert.Equal(t.T(), body, string(b))
+
+	request, _ = http.NewRequest(POST.String(), "/device/network", strings.NewReader(`{"port":`))
+	request.Header.Set("Content-Type", MediaTypeJSON.String())
+
+	err = form.ValidateRequest(request)
+
+	_, ok = err.(*ErrValidation)
+	assert.NotNil(t.T(), err)
+	assert.False(t.T(), ok)
+}
+
+func (t *Test_Form_when_validating) Test_json_array_is_one_value_for_single_field() {
+	form := &Form{Action: "/device/env", Method: POST, Enctype: MediaTypeJSON}
+	form.Field("env", MediaTypeHMAPIArray).Require()
+
+	request, _ := http.NewRequest(POST.String(), "/device/env", strings.NewReader(`{"env":["A=1","B=2"]}`))
+	request.Header.Set("Content-Type", MediaTypeJSON.String())
+
+	assert.Nil(t.T(), form.ValidateRequest(request))
+}
+
+func (t *Test_Form_when_validating) Test_bracket_notation_read_for_structured_fields() {
+	form := &Form{Action: "/device/network", Method: POST, Enctype: MediaTypeFormURLEncoded}
+	form.Field("net", MediaTypeHMAPIObject).Require()
+	form.Field("env", MediaTypeHMAPIArray).Require().WithLength(2, 2)
+
+	request, _ := http.NewRequest(POST.String(), "/device/network", strings.NewReader(url.Values{
+		"net[ip]":     []string{"10.0.0.2"},
+		"net[dns][0]": []string{"1.1.1.1"},
+		"env[0]":      []string{"A=1"},
+		"env[1]":      []string{"B=2"},
+	}.Encode()))
+	request.Header.Set("Content-Type", MediaTypeFormURLEncoded.String())
+
+	assert.Nil(t.T(), form.ValidateRequest(request))
+
+	request, _ = http.NewRequest(POST.String(), "/device/network", strings.NewReader(url.Values{
+		"net[ip]":     []string{"10.0.0.2"},
+		"net[ip][v4]": []string{"10.0.0.2"},
+		"env[0]":      []string{"A=1"},
+		"env[2]":      []string{"B=2"},
+	}.Encode()))
+	request.Header.Set("Content-Type", MediaTypeFormURLEncoded.String())
+
+	err := form.ValidateRequest(request)
+
+	e, ok := err.(*ErrInvalidFieldValue)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), "net", e.FieldName)
+}
+
+func (t *Test_Form_when_validating) Test_reports_malformed_multipart_body() {
+	form := &Form{Action: "/device/network", Method: POST, Enctype: MediaTypeMultipartFormData}
+	form.Field("port", MediaTypeHMAPIInt)
+
+	request, _ := http.NewRequest(POST.String(), "/device/network", strings.NewReader("--x\r\nbroken"))
+	request.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+
+	err := form.ValidateRequest(request)
+
+	_, ok := err.(*ErrValidation)
+	assert.NotNil(t.T(), err)
+	assert.False(t.T(), ok)
+}
+
+func (t *Test_Form_when_validating) Test_invalid_pattern_reported_once() {
+	field := &FormField{Name: "dns", Type: MediaTypeHMAPIString, Multiple: true, Pattern: "("}
+
+	assert.Equal(t.T(), 1, len(field.validate([]interface{}{"a", "b"})))
+}
+
+func (t *Test_Form_when_validating) Test_options_compared_by_field_encoding() {
+	field := &FormField{Name: "level", Type: MediaTypeHMAPIInt}
+	field.WithOption(float64(1), "low").WithOption(float64(2), "high")
+
+	assert.Equal(t.T(), []string{}, field.validate([]interface{}{int64(2)}))
+	assert.Equal(t.T(), []string{"is not one of the permitted options"}, field.validate([]interface{}{int64(3)}))
+}
+
+func TestRunValidateTestSuites(t *testing.T) {
+	suite.Run(t, new(Test_Form_when_validating))
+}